@@ -1,9 +1,12 @@
 package alarm
 
 import (
+	"os"
+	"slices"
 	"sync"
 	"time"
 	"wecker/config"
+	"wecker/state"
 )
 
 // AlarmState represents the current state of an alarm
@@ -14,39 +17,118 @@ const (
 	StateActive
 	StateSnoozed
 	StateTriggered
+	StatePreAlarm // Quiet wake window phase before the alarm time
 )
 
-const appLoopInterval = 5
+const (
+	appLoopInterval    = 5 // Seconds between housekeeping checks (snooze, ring timeout, clock jumps)
+	clockJumpThreshold = 2 * time.Second
+	idleWait           = 24 * time.Hour // Scheduler wait when nothing is planned
+	autoSnoozeLimit    = 3              // Auto-snoozes of alarms without MaxSnoozes before they stop
+)
 
 // ActiveAlarm represents an alarm that is currently running
 type ActiveAlarm struct {
-	Alarm       *config.Alarm
+	Alarm       *config.Alarm // Copy of the alarm taken when it triggered
 	State       AlarmState
 	StartTime   time.Time
+	RingStart   time.Time // Start of the current ring period, reset after each snooze
 	SnoozeUntil time.Time
 	Duration    time.Duration
+	SnoozeCount int       // Snoozes so far, manual and automatic
+	AutoSnoozes int       // Snoozes triggered because nobody reacted
+	Escalated   bool      // Rings at full volume after NoResponseEscalate
+	Stage       int       // Current index into Alarm.Stages
+	StageStart  time.Time // Start of the current stage while ringing
+	Deadline    time.Time // Alarm time at which a wake window ends
+}
+
+// SnoozesLeft returns how many more snoozes are allowed, or -1 if snoozing is unlimited
+func (a *ActiveAlarm) SnoozesLeft() int {
+	if a.Alarm.MaxSnoozes <= 0 {
+		return -1
+	}
+	return max(0, a.Alarm.MaxSnoozes-a.SnoozeCount)
 }
 
-// Manager manages the alarm system
+// stageEnd returns when a ringing alarm advances to its next stage. The last stage and
+// escalated alarms, which ring at full volume, don't advance.
+func (a *ActiveAlarm) stageEnd() (time.Time, bool) {
+	stageDuration := a.Alarm.StageDuration(a.Stage)
+	if stageDuration <= 0 || a.Escalated {
+		return time.Time{}, false
+	}
+	return a.StageStart.Add(stageDuration), true
+}
+
+// Manager manages the alarm system. It plans from its own copy of the alarm list and
+// settings, the UI edits the config and hands over new copies with UpdateAlarms and
+// UpdateSettings.
 type Manager struct {
-	config       *config.Config
+	alarms       []config.Alarm
+	settings     settings
 	activeAlarms map[int]*ActiveAlarm
+	nextFire     map[int]time.Time // Planned next occurrence per alarm ID
+	missed       map[int]time.Time // Missed occurrences waiting to be acknowledged
+	skipped      map[int]SkippedOccurrence
+	holidays     *HolidayCalendar
+	holidayErr   error
+	state        *state.State
+	replan       chan struct{}
 	mutex        sync.RWMutex
 	callbacks    AlarmCallbacks
 }
 
+// settings is the manager's copy of the global settings the scheduler reads
+type settings struct {
+	snoozeMinutes int
+	missedGrace   time.Duration
+	missedAction  config.MissedAlarmAction
+	activityFile  string
+	holidays      []string // Exception dates, YYYY-MM-DD
+	holidayFiles  []string // .ics calendars
+}
+
+// settingsOf copies the scheduler settings out of cfg
+func settingsOf(cfg *config.Config) settings {
+	return settings{
+		snoozeMinutes: cfg.SnoozeMinutes,
+		missedGrace:   time.Duration(cfg.MissedAlarmGraceMinutes) * time.Minute,
+		missedAction:  cfg.MissedAlarmAction,
+		activityFile:  cfg.ActivityFile,
+		holidays:      slices.Clone(cfg.Holidays),
+		holidayFiles:  slices.Clone(cfg.HolidayFiles),
+	}
+}
+
 // AlarmCallbacks defines callback functions for alarm events
 type AlarmCallbacks struct {
 	OnAlarmTriggered func(alarmID int, alarm *config.Alarm)
 	OnAlarmSnoozed   func(alarmID int, duration time.Duration)
 	OnAlarmStopped   func(alarmID int)
+	OnAlarmMissed    func(alarmID int, occurrence time.Time)
+	OnAlarmEscalated func(alarmID int, alarm *config.Alarm)
+	// OnAlarmStageChanged is called when a ringing alarm advances to its next stage
+	OnAlarmStageChanged func(alarmID int, alarm *config.Alarm, stage int)
+	// OnPreAlarmStarted is called at the start of a wake window, the alarm rings fully at deadline
+	OnPreAlarmStarted func(alarmID int, alarm *config.Alarm, deadline time.Time)
+	// OnAlarmDisabled is called when a one-shot alarm disabled itself after ringing, the
+	// configured alarm should be disabled as well
+	OnAlarmDisabled func(alarmID int)
 }
 
-// NewManager creates a new alarm manager
-func NewManager(cfg *config.Config) *Manager {
+// NewManager creates a new alarm manager; st records when alarms fired and which
+// alarms were active across restarts
+func NewManager(cfg *config.Config, st *state.State) *Manager {
 	return &Manager{
-		config:       cfg,
+		alarms:       cfg.CloneAlarms(),
+		settings:     settingsOf(cfg),
+		state:        st,
 		activeAlarms: make(map[int]*ActiveAlarm),
+		nextFire:     make(map[int]time.Time),
+		missed:       make(map[int]time.Time),
+		skipped:      make(map[int]SkippedOccurrence),
+		replan:       make(chan struct{}, 1),
 	}
 }
 
@@ -60,50 +142,234 @@ func (m *Manager) Start() {
 	go m.monitorLoop()
 }
 
-// monitorLoop continuously checks for alarm triggers
+// monitorLoop sleeps until the next planned alarm or housekeeping deadline. It re-plans
+// when the configuration changes or the wall clock jumps (clock set, resume from suspend).
 func (m *Manager) monitorLoop() {
-	ticker := time.NewTicker(appLoopInterval * time.Second)
-	defer ticker.Stop()
+	watchdog := time.NewTicker(appLoopInterval * time.Second)
+	defer watchdog.Stop()
+
+	m.reloadHolidays()
+	last := time.Now()
+	m.restoreActive(last)
+	m.detectMissed(time.Time{}, last)
+	m.schedule(last)
 
 	for {
+		wakeup := time.NewTimer(m.untilNextEvent())
+
+		due, replan := false, false
 		select {
-		case now := <-ticker.C:
+		case <-wakeup.C:
+			due = true
+		case <-m.replan:
+			replan = true
+		case <-watchdog.C:
+		}
+		wakeup.Stop()
+
+		now := time.Now()
+		switch {
+		case clockJumped(last, now):
+			// Occurrences planned before the jump are no longer meaningful
+			m.detectMissed(last.Round(0), now)
+			m.schedule(now)
+		case replan:
+			m.reloadHolidays()
+			m.schedule(now)
+		case due:
 			m.checkAlarms(now)
-			m.updateActiveAlarms(now)
 		}
+		m.checkActivityFile()
+		m.updateActiveAlarms(now)
+		last = now
 	}
 }
 
-// checkAlarms checks if any alarms should be triggered
-func (m *Manager) checkAlarms(now time.Time) {
+// schedule recomputes the next occurrence of every enabled alarm
+func (m *Manager) schedule(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextFire = make(map[int]time.Time)
+	m.skipped = make(map[int]SkippedOccurrence)
+	for i := range m.alarms {
+		a := &m.alarms[i]
+		if !a.Enabled {
+			continue
+		}
+		next, skipped := m.planNext(a, now)
+		if !next.IsZero() {
+			m.nextFire[a.ID] = next
+		}
+		if skipped != nil {
+			m.skipped[a.ID] = *skipped
+		}
+	}
+}
+
+// detectMissed looks for occurrences between since and now that never rang because wecker
+// wasn't running or the machine was suspended. Only the grace window before now is searched.
+func (m *Manager) detectMissed(since, now time.Time) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	// Check Alarm 1
-	if m.config.Alarm1.IsAlarmActive(now) {
-		if _, exists := m.activeAlarms[1]; !exists {
-			m.triggerAlarm(1, &m.config.Alarm1, now)
+	grace := m.settings.missedGrace
+	if grace <= 0 {
+		return
+	}
+
+	for i := range m.alarms {
+		a := &m.alarms[i]
+		if !a.Enabled {
+			continue
+		}
+
+		from := now.Add(-grace)
+		if since.After(from) {
+			from = since
+		}
+		if lastFired := m.state.GetLastFired(a.ID); lastFired.After(from) {
+			from = lastFired
+		}
+
+		// Occurrences on holidays weren't supposed to ring in the first place
+		occurrence, _ := m.planNext(a, from)
+		if occurrence.IsZero() || occurrence.After(now) || m.skipOccurrence(a, occurrence) != nil {
+			continue
+		}
+
+		if m.settings.missedAction == config.MissedAlarmRing {
+			if _, exists := m.activeAlarms[a.ID]; !exists {
+				m.triggerAlarm(a.ID, a, now, time.Time{})
+			}
+			continue
+		}
+
+		// The notice counts as handling the occurrence, so a restart doesn't report it again
+		m.missed[a.ID] = occurrence
+		m.recordFired(a.ID, occurrence)
+		if m.callbacks.OnAlarmMissed != nil {
+			go m.callbacks.OnAlarmMissed(a.ID, occurrence)
+		}
+	}
+}
+
+// recordFired persists the time an alarm occurrence was handled (internal, assumes mutex is held)
+func (m *Manager) recordFired(alarmID int, t time.Time) {
+	m.state.SetLastFired(alarmID, t)
+	go m.state.Save()
+}
+
+// untilNextEvent returns how long the scheduler may sleep before something is due
+func (m *Manager) untilNextEvent() time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var earliest time.Time
+	consider := func(t time.Time) {
+		if earliest.IsZero() || t.Before(earliest) {
+			earliest = t
 		}
 	}
 
-	// Check Alarm 2
-	if m.config.Alarm2.IsAlarmActive(now) {
-		if _, exists := m.activeAlarms[2]; !exists {
-			m.triggerAlarm(2, &m.config.Alarm2, now)
+	for id, next := range m.nextFire {
+		if a := m.findAlarm(id); a != nil {
+			next = next.Add(-a.WakeWindow())
+		}
+		consider(next)
+	}
+	for _, activeAlarm := range m.activeAlarms {
+		switch activeAlarm.State {
+		case StateTriggered:
+			consider(activeAlarm.RingStart.Add(activeAlarm.Alarm.RingDuration()))
+			if stageEnd, ok := activeAlarm.stageEnd(); ok {
+				consider(stageEnd)
+			}
+		case StateSnoozed:
+			consider(activeAlarm.SnoozeUntil)
+		case StatePreAlarm:
+			consider(activeAlarm.Deadline)
+		}
+	}
+
+	if earliest.IsZero() {
+		return idleWait
+	}
+	return max(0, time.Until(earliest))
+}
+
+// checkAlarms triggers every alarm whose planned occurrence, or the start of its
+// wake window, has been reached
+func (m *Manager) checkAlarms(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.alarms {
+		a := &m.alarms[i]
+		next, planned := m.nextFire[a.ID]
+		if !planned || next.Add(-a.WakeWindow()).After(now) {
+			continue
+		}
+
+		// The holiday calendar or skip may have changed since the occurrence was planned
+		if skip := m.skipOccurrence(a, next); skip != nil {
+			m.skipped[a.ID] = *skip
+		} else if _, exists := m.activeAlarms[a.ID]; !exists && a.Enabled {
+			m.triggerAlarm(a.ID, a, now, next)
+		}
+
+		// Plan the occurrence following the one just handled, which may still lie ahead in a wake window
+		after := now
+		if next.After(after) {
+			after = next
+		}
+		delete(m.nextFire, a.ID)
+		following, skipped := m.planNext(a, after)
+		if !following.IsZero() && a.Enabled {
+			m.nextFire[a.ID] = following
+		}
+		if skipped != nil {
+			m.skipped[a.ID] = *skipped
 		}
 	}
 }
 
-// triggerAlarm triggers a specific alarm
-func (m *Manager) triggerAlarm(alarmID int, alarm *config.Alarm, now time.Time) {
+// triggerAlarm triggers a specific alarm. With a deadline still ahead the alarm starts
+// in its wake window and only rings fully once the deadline is reached.
+func (m *Manager) triggerAlarm(alarmID int, alarm *config.Alarm, now time.Time, deadline time.Time) {
+	// Keep a snapshot, the alarm list may be replaced while the alarm rings
+	alarmCopy := alarm.Clone()
+
+	// One-shot alarms disable themselves once they rang
+	if alarm.IsOneShot() {
+		alarm.Enabled = false
+		if m.callbacks.OnAlarmDisabled != nil {
+			go m.callbacks.OnAlarmDisabled(alarmID)
+		}
+	}
+	alarm = &alarmCopy
+
 	activeAlarm := &ActiveAlarm{
-		Alarm:     alarm,
-		State:     StateTriggered,
-		StartTime: now,
-		Duration:  0,
+		Alarm:      alarm,
+		State:      StateTriggered,
+		StartTime:  now,
+		RingStart:  now,
+		StageStart: now,
+		Duration:   0,
 	}
 
 	m.activeAlarms[alarmID] = activeAlarm
+	m.recordFired(alarmID, now)
+	defer m.persistActive()
+
+	if deadline.After(now) {
+		activeAlarm.State = StatePreAlarm
+		activeAlarm.Deadline = deadline
+		if m.callbacks.OnPreAlarmStarted != nil {
+			go m.callbacks.OnPreAlarmStarted(alarmID, alarm, deadline)
+		}
+		return
+	}
 
 	// Call trigger callback
 	if m.callbacks.OnAlarmTriggered != nil {
@@ -111,6 +377,54 @@ func (m *Manager) triggerAlarm(alarmID int, alarm *config.Alarm, now time.Time)
 	}
 }
 
+// endWakeWindow lets an alarm in its wake window ring at full volume
+// (internal, assumes mutex is held)
+func (m *Manager) endWakeWindow(alarmID int, activeAlarm *ActiveAlarm, now time.Time) {
+	activeAlarm.State = StateTriggered
+	activeAlarm.RingStart = now
+	activeAlarm.StageStart = now
+	m.recordFired(alarmID, now)
+	m.persistActive()
+
+	if m.callbacks.OnAlarmTriggered != nil {
+		go m.callbacks.OnAlarmTriggered(alarmID, activeAlarm.Alarm)
+	}
+}
+
+// NotifyActivity reports that the sleeper is awake, e.g. a key press. Alarms in their
+// wake window stop waiting for the deadline and ring at full volume right away.
+func (m *Manager) NotifyActivity() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for alarmID, activeAlarm := range m.activeAlarms {
+		if activeAlarm.State == StatePreAlarm {
+			m.endWakeWindow(alarmID, activeAlarm, now)
+		}
+	}
+}
+
+// checkActivityFile treats a touch of the configured activity file during a wake window as activity
+func (m *Manager) checkActivityFile() {
+	m.mutex.RLock()
+	path := m.settings.activityFile
+	var windowStart time.Time
+	for _, activeAlarm := range m.activeAlarms {
+		if activeAlarm.State == StatePreAlarm && (windowStart.IsZero() || activeAlarm.StartTime.Before(windowStart)) {
+			windowStart = activeAlarm.StartTime
+		}
+	}
+	m.mutex.RUnlock()
+
+	if path == "" || windowStart.IsZero() {
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.ModTime().After(windowStart) {
+		m.NotifyActivity()
+	}
+}
+
 // updateActiveAlarms updates the state of currently active alarms
 func (m *Manager) updateActiveAlarms(now time.Time) {
 	m.mutex.Lock()
@@ -119,16 +433,36 @@ func (m *Manager) updateActiveAlarms(now time.Time) {
 	for alarmID, activeAlarm := range m.activeAlarms {
 		switch activeAlarm.State {
 		case StateTriggered:
-			// Check if alarm has been running for 60 minutes
-			if now.Sub(activeAlarm.StartTime) >= 60*time.Minute {
-				m.stopAlarmInternal(alarmID)
+			// Check if alarm has been ringing for its maximum ring time
+			if now.Sub(activeAlarm.RingStart) >= activeAlarm.Alarm.RingDuration() {
+				m.handleNoResponse(alarmID, activeAlarm, now)
+				continue
+			}
+
+			// Advance to the next stage of an escalating alarm
+			if stageEnd, ok := activeAlarm.stageEnd(); ok && !now.Before(stageEnd) {
+				activeAlarm.Stage++
+				activeAlarm.StageStart = now
+				m.persistActive()
+				if m.callbacks.OnAlarmStageChanged != nil {
+					go m.callbacks.OnAlarmStageChanged(alarmID, activeAlarm.Alarm, activeAlarm.Stage)
+				}
+			}
+
+		case StatePreAlarm:
+			// The wake window ends at the alarm time
+			if !now.Before(activeAlarm.Deadline) {
+				m.endWakeWindow(alarmID, activeAlarm, now)
 			}
 
 		case StateSnoozed:
 			// Check if snooze time has elapsed
-			if now.After(activeAlarm.SnoozeUntil) {
-				// Re-trigger the alarm
+			if !now.Before(activeAlarm.SnoozeUntil) {
+				// Re-trigger the alarm, it continues at the stage it was snoozed in
 				activeAlarm.State = StateTriggered
+				activeAlarm.RingStart = now
+				activeAlarm.StageStart = now
+				m.persistActive()
 				if m.callbacks.OnAlarmTriggered != nil {
 					go m.callbacks.OnAlarmTriggered(alarmID, activeAlarm.Alarm)
 				}
@@ -137,27 +471,62 @@ func (m *Manager) updateActiveAlarms(now time.Time) {
 	}
 }
 
-// SnoozeAlarm snoozes an active alarm
+// handleNoResponse applies the alarm's no-response action once its ring time is over
+// (internal, assumes mutex is held)
+func (m *Manager) handleNoResponse(alarmID int, activeAlarm *ActiveAlarm, now time.Time) {
+	switch activeAlarm.Alarm.NoResponseAction {
+	case config.NoResponseSnooze:
+		// Alarms without a snooze limit still give up after a few unanswered rings
+		limitReached := activeAlarm.SnoozesLeft() == 0 ||
+			(activeAlarm.SnoozesLeft() < 0 && activeAlarm.AutoSnoozes >= autoSnoozeLimit)
+		if !limitReached {
+			activeAlarm.AutoSnoozes++
+			m.snoozeInternal(alarmID, activeAlarm, now)
+			return
+		}
+
+	case config.NoResponseEscalate:
+		if !activeAlarm.Escalated {
+			activeAlarm.Escalated = true
+			activeAlarm.RingStart = now
+			m.persistActive()
+			if m.callbacks.OnAlarmEscalated != nil {
+				go m.callbacks.OnAlarmEscalated(alarmID, activeAlarm.Alarm)
+			}
+			return
+		}
+	}
+
+	m.stopAlarmInternal(alarmID)
+}
+
+// SnoozeAlarm snoozes an active alarm. It is refused once the alarm's snooze limit is reached.
 func (m *Manager) SnoozeAlarm(alarmID int) bool {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	activeAlarm, exists := m.activeAlarms[alarmID]
-	if !exists || activeAlarm.State != StateTriggered {
+	if !exists || activeAlarm.State != StateTriggered || activeAlarm.SnoozesLeft() == 0 {
 		return false
 	}
 
-	// Set snooze duration
-	snoozeDuration := time.Duration(m.config.SnoozeMinutes) * time.Minute
+	m.snoozeInternal(alarmID, activeAlarm, time.Now())
+	return true
+}
+
+// snoozeInternal snoozes a ringing alarm (internal, assumes mutex is held)
+func (m *Manager) snoozeInternal(alarmID int, activeAlarm *ActiveAlarm, now time.Time) {
+	// Set snooze duration, later snoozes may be shorter with progressive snooze
+	snoozeDuration := activeAlarm.Alarm.SnoozeDuration(activeAlarm.SnoozeCount, m.settings.snoozeMinutes)
 	activeAlarm.State = StateSnoozed
-	activeAlarm.SnoozeUntil = time.Now().Add(snoozeDuration)
+	activeAlarm.SnoozeUntil = now.Add(snoozeDuration)
+	activeAlarm.SnoozeCount++
+	m.persistActive()
 
 	// Call snooze callback
 	if m.callbacks.OnAlarmSnoozed != nil {
 		go m.callbacks.OnAlarmSnoozed(alarmID, snoozeDuration)
 	}
-
-	return true
 }
 
 // StopAlarm stops an active alarm
@@ -176,6 +545,7 @@ func (m *Manager) stopAlarmInternal(alarmID int) bool {
 	}
 
 	delete(m.activeAlarms, alarmID)
+	m.persistActive()
 
 	// Call stop callback
 	if m.callbacks.OnAlarmStopped != nil {
@@ -185,6 +555,17 @@ func (m *Manager) stopAlarmInternal(alarmID int) bool {
 	return true
 }
 
+// GetStage returns the current stage of an active alarm, 0 if it isn't active
+func (m *Manager) GetStage(alarmID int) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if activeAlarm, exists := m.activeAlarms[alarmID]; exists {
+		return activeAlarm.Stage
+	}
+	return 0
+}
+
 // GetActiveAlarms returns a copy of currently active alarms
 func (m *Manager) GetActiveAlarms() map[int]*ActiveAlarm {
 	m.mutex.RLock()
@@ -223,8 +604,10 @@ func (m *Manager) GetAlarmState(alarmID int) AlarmState {
 
 // SetSnoozeTime updates the snooze duration
 func (m *Manager) SetSnoozeTime(minutes int) {
-	m.config.SnoozeMinutes = minutes
-	return
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.settings.snoozeMinutes = minutes
 }
 
 // GetSnoozeTimeRemaining returns remaining snooze time for an alarm
@@ -242,9 +625,111 @@ func (m *Manager) GetSnoozeTimeRemaining(alarmID int) time.Duration {
 	return 0
 }
 
-// UpdateConfig updates the configuration reference
+// UpdateConfig replaces the alarms and settings with copies from a new configuration
 func (m *Manager) UpdateConfig(cfg *config.Config) {
+	m.mutex.Lock()
+	m.alarms = cfg.CloneAlarms()
+	m.settings = settingsOf(cfg)
+	m.mutex.Unlock()
+
+	m.Reschedule()
+}
+
+// UpdateSettings replaces the manager's copy of the global settings and re-plans; call
+// it after the snooze, missed alarm, activity file or holiday settings changed
+func (m *Manager) UpdateSettings(cfg *config.Config) {
+	s := settingsOf(cfg)
+
+	m.mutex.Lock()
+	m.settings = s
+	m.mutex.Unlock()
+
+	m.Reschedule()
+}
+
+// UpdateAlarms replaces the alarms the scheduler plans with a copy of alarms; call it
+// after alarms were added, edited or deleted
+func (m *Manager) UpdateAlarms(alarms []config.Alarm) {
+	snapshot := make([]config.Alarm, len(alarms))
+	for i, a := range alarms {
+		snapshot[i] = a.Clone()
+	}
+
+	m.mutex.Lock()
+	m.alarms = snapshot
+	m.mutex.Unlock()
+
+	m.Reschedule()
+}
+
+// findAlarm returns the alarm with the given ID from the scheduler's copy, or nil
+// (internal, assumes mutex is held)
+func (m *Manager) findAlarm(id int) *config.Alarm {
+	for i := range m.alarms {
+		if m.alarms[i].ID == id {
+			return &m.alarms[i]
+		}
+	}
+	return nil
+}
+
+// Reschedule makes the scheduler recompute all next fire times; call it after alarms changed
+func (m *Manager) Reschedule() {
+	select {
+	case m.replan <- struct{}{}:
+	default:
+		// A re-plan is already pending
+	}
+}
+
+// GetMissedAlarms returns a copy of the missed occurrences that were not acknowledged yet
+func (m *Manager) GetMissedAlarms() map[int]time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := make(map[int]time.Time)
+	for id, occurrence := range m.missed {
+		result[id] = occurrence
+	}
+
+	return result
+}
+
+// DismissMissed acknowledges all missed alarm notices
+func (m *Manager) DismissMissed() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	m.config = cfg
+	m.missed = make(map[int]time.Time)
+}
+
+// NextOccurrence returns the occurrence the alarm would ring at next, passing over
+// occurrences that are already skipped. Setting SkipUntil to it skips that occurrence
+// as well, without disabling the alarm.
+func (m *Manager) NextOccurrence(alarmID int) (time.Time, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	a := m.findAlarm(alarmID)
+	if a == nil {
+		return time.Time{}, false
+	}
+
+	next, _ := m.planNext(a, time.Now())
+	if next.IsZero() || m.skipOccurrence(a, next) != nil {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// NextFire returns when the alarm will ring next, or the zero time if it isn't scheduled
+func (m *Manager) NextFire(alarmID int) time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// After a long run of skipped occurrences the plan is only the point to plan on from
+	next, planned := m.nextFire[alarmID]
+	if a := m.findAlarm(alarmID); !planned || a == nil || m.skipOccurrence(a, next) != nil {
+		return time.Time{}
+	}
+	return next
 }
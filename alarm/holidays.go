@@ -0,0 +1,244 @@
+package alarm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"wecker/config"
+)
+
+// maxHolidaySkips bounds how many consecutive holiday occurrences are passed over when planning
+const maxHolidaySkips = 366
+
+// Holiday is a single day on which holiday-aware alarms stay quiet
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// yearlyHoliday is a holiday that repeats on the same day every year (FREQ=YEARLY in .ics files)
+type yearlyHoliday struct {
+	month time.Month
+	day   int
+	from  string // First date (YYYY-MM-DD) the holiday applies
+	until string // Last date it applies, empty for no end
+	name  string
+}
+
+// HolidayCalendar answers whether a day is a holiday. It combines the local exception
+// dates from the config with holidays imported from .ics files. A nil calendar is empty.
+type HolidayCalendar struct {
+	dates  map[string]string // YYYY-MM-DD -> holiday name
+	yearly []yearlyHoliday
+}
+
+// LoadHolidays builds a calendar from exception dates (YYYY-MM-DD) and .ics files.
+// Entries that fail to load are reported in the error, the rest is still returned.
+func LoadHolidays(dates []string, files []string) (*HolidayCalendar, error) {
+	calendar := &HolidayCalendar{dates: make(map[string]string)}
+	var errs []error
+
+	for _, date := range dates {
+		if _, err := config.ParseDate(date, time.Local); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		calendar.dates[date] = "Day off"
+	}
+
+	for _, path := range files {
+		if err := calendar.importICS(path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to import %s: %v", path, err))
+		}
+	}
+
+	return calendar, errors.Join(errs...)
+}
+
+// Holiday returns the name of the holiday on the given day, if it is one
+func (c *HolidayCalendar) Holiday(day time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	date := day.Format(config.DateLayout)
+	if name, exists := c.dates[date]; exists {
+		return name, true
+	}
+	for _, h := range c.yearly {
+		if day.Month() == h.month && day.Day() == h.day && date >= h.from && (h.until == "" || date <= h.until) {
+			return h.name, true
+		}
+	}
+	return "", false
+}
+
+// Upcoming returns the holidays within the given number of days starting at from
+func (c *HolidayCalendar) Upcoming(from time.Time, days int) []Holiday {
+	var holidays []Holiday
+	first := startOfDay(from)
+	for offset := 0; offset < days; offset++ {
+		day := first.AddDate(0, 0, offset)
+		if name, isHoliday := c.Holiday(day); isHoliday {
+			holidays = append(holidays, Holiday{Date: day, Name: name})
+		}
+	}
+	return holidays
+}
+
+// importICS adds the all-day events of an iCalendar file. Events spanning several days
+// mark every day up to DTEND (exclusive); FREQ=YEARLY rules repeat them every year.
+// Malformed events are reported in the error, the other events are still imported.
+func (c *HolidayCalendar) importICS(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	lines, err := unfoldICS(file)
+	if err != nil {
+		return err
+	}
+
+	var event map[string]string
+	var errs []error
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = make(map[string]string)
+		case line == "END:VEVENT":
+			if event != nil {
+				if err := c.addEvent(event); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			event = nil
+		case event != nil:
+			// NAME;PARAM=VALUE:value - parameters are not needed for all-day events
+			name, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			name, _, _ = strings.Cut(name, ";")
+			event[strings.ToUpper(name)] = value
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// addEvent adds a parsed VEVENT to the calendar
+func (c *HolidayCalendar) addEvent(event map[string]string) error {
+	start, err := parseICSDate(event["DTSTART"])
+	if err != nil {
+		return fmt.Errorf("invalid DTSTART %q: %v", event["DTSTART"], err)
+	}
+
+	end := start.AddDate(0, 0, 1)
+	if value, exists := event["DTEND"]; exists {
+		if end, err = parseICSDate(value); err != nil {
+			return fmt.Errorf("invalid DTEND %q: %v", value, err)
+		}
+	}
+
+	name := icsUnescaper.Replace(event["SUMMARY"])
+	if name == "" {
+		name = "Holiday"
+	}
+
+	yearly, until := false, ""
+	if rule, exists := event["RRULE"]; exists {
+		for _, part := range strings.Split(rule, ";") {
+			key, value, _ := strings.Cut(part, "=")
+			switch strings.ToUpper(key) {
+			case "FREQ":
+				yearly = strings.ToUpper(value) == "YEARLY"
+			case "UNTIL":
+				if day, err := parseICSDate(value); err == nil {
+					until = day.Format(config.DateLayout)
+				}
+			}
+		}
+		if !yearly {
+			// Only yearly repeating holidays are supported, import the first instance
+			until = ""
+		}
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(config.DateLayout)
+		if yearly {
+			c.yearly = append(c.yearly, yearlyHoliday{month: day.Month(), day: day.Day(), from: date, until: until, name: name})
+			continue
+		}
+		c.dates[date] = name
+	}
+
+	return nil
+}
+
+// icsUnescaper resolves the escaped characters of iCalendar text values
+var icsUnescaper = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`)
+
+// unfoldICS reads the content lines of an iCalendar file, joining folded continuation lines
+func unfoldICS(file *os.File) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSDate parses the date part of an iCalendar DATE or DATE-TIME value in local time
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("too short")
+	}
+	return time.ParseInLocation("20060102", value[:8], time.Local)
+}
+
+// reloadHolidays re-reads the exception dates and .ics files of the settings
+func (m *Manager) reloadHolidays() {
+	m.mutex.RLock()
+	dates := m.settings.holidays
+	files := m.settings.holidayFiles
+	m.mutex.RUnlock()
+
+	calendar, err := LoadHolidays(dates, files)
+
+	m.mutex.Lock()
+	m.holidays = calendar
+	m.holidayErr = err
+	m.mutex.Unlock()
+}
+
+// Holidays returns the loaded holiday calendar and the error of the last reload, if any
+func (m *Manager) Holidays() (*HolidayCalendar, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.holidays, m.holidayErr
+}
+
+// GetSkipped returns a copy of the upcoming or recent occurrences the scheduler passed over
+func (m *Manager) GetSkipped() map[int]SkippedOccurrence {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := make(map[int]SkippedOccurrence)
+	for id, skipped := range m.skipped {
+		result[id] = skipped
+	}
+
+	return result
+}
@@ -0,0 +1,134 @@
+package alarm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wecker/config"
+)
+
+// icsCalendar wraps events in a VCALENDAR with CRLF line endings
+func icsCalendar(events ...string) string {
+	content := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"
+	for _, event := range events {
+		content += "BEGIN:VEVENT\r\n" + event + "END:VEVENT\r\n"
+	}
+	return content + "END:VCALENDAR\r\n"
+}
+
+func TestLoadHolidaysICS(t *testing.T) {
+	tests := []struct {
+		name     string
+		ics      string
+		holidays map[string]string // Date -> name
+		workdays []string
+		wantErr  bool
+	}{
+		{
+			name: "all-day event with VALUE=DATE",
+			ics: icsCalendar(
+				"DTSTART;VALUE=DATE:20261225\r\nDTEND;VALUE=DATE:20261226\r\nSUMMARY:Christmas Day\r\n",
+			),
+			holidays: map[string]string{"2026-12-25": "Christmas Day"},
+			workdays: []string{"2026-12-24", "2026-12-26"},
+		},
+		{
+			name: "event without DTEND lasts one day",
+			ics: icsCalendar(
+				"DTSTART:20260501\r\nSUMMARY:Labour Day\r\n",
+			),
+			holidays: map[string]string{"2026-05-01": "Labour Day"},
+			workdays: []string{"2026-05-02"},
+		},
+		{
+			name: "multi-day event ends before DTEND",
+			ics: icsCalendar(
+				"DTSTART;VALUE=DATE:20260803\r\nDTEND;VALUE=DATE:20260806\r\nSUMMARY:Summer break\r\n",
+			),
+			holidays: map[string]string{"2026-08-03": "Summer break", "2026-08-04": "Summer break", "2026-08-05": "Summer break"},
+			workdays: []string{"2026-08-02", "2026-08-06"},
+		},
+		{
+			name: "yearly rule with UNTIL",
+			ics: icsCalendar(
+				"DTSTART;VALUE=DATE:20250101\r\nRRULE:FREQ=YEARLY;UNTIL=20270101\r\nSUMMARY:New Year\r\n",
+			),
+			holidays: map[string]string{"2025-01-01": "New Year", "2026-01-01": "New Year", "2027-01-01": "New Year"},
+			workdays: []string{"2024-01-01", "2028-01-01"},
+		},
+		{
+			name: "folded and escaped summary",
+			ics: icsCalendar(
+				"DTSTART;VALUE=DATE:20261003\r\nSUMMARY:Day of German\r\n  Unity\\, national holiday\r\n",
+			),
+			holidays: map[string]string{"2026-10-03": "Day of German Unity, national holiday"},
+		},
+		{
+			name: "malformed event is reported and the others are kept",
+			ics: icsCalendar(
+				"DTSTART;VALUE=DATE:20260101\r\nSUMMARY:New Year\r\n",
+				"DTSTART;VALUE=DATE:2026\r\nSUMMARY:Broken\r\n",
+				"DTSTART;VALUE=DATE:20261225\r\nDTEND;VALUE=DATE:garbage\r\nSUMMARY:Broken end\r\n",
+				"DTSTART;VALUE=DATE:20261231\r\nSUMMARY:New Year's Eve\r\n",
+			),
+			holidays: map[string]string{"2026-01-01": "New Year", "2026-12-31": "New Year's Eve"},
+			workdays: []string{"2026-12-25"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "holidays.ics")
+			if err := os.WriteFile(path, []byte(tt.ics), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			calendar, err := LoadHolidays(nil, []string{path})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadHolidays error = %v, want error %v", err, tt.wantErr)
+			}
+			for date, want := range tt.holidays {
+				day, _ := config.ParseDate(date, time.Local)
+				if name, isHoliday := calendar.Holiday(day); !isHoliday || name != want {
+					t.Errorf("Holiday(%s) = %q, %v, want %q", date, name, isHoliday, want)
+				}
+			}
+			for _, date := range tt.workdays {
+				day, _ := config.ParseDate(date, time.Local)
+				if name, isHoliday := calendar.Holiday(day); isHoliday {
+					t.Errorf("Holiday(%s) = %q, want no holiday", date, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHoliday(t *testing.T) {
+	calendar, err := LoadHolidays([]string{"2026-06-15", "not a date"}, []string{filepath.Join(t.TempDir(), "missing.ics")})
+	if err == nil {
+		t.Error("LoadHolidays succeeded with an invalid date and a missing file, want an error")
+	}
+
+	tokyo := mustLoad(t, "Asia/Tokyo")
+	tests := []struct {
+		name     string
+		calendar *HolidayCalendar
+		day      time.Time
+		want     bool
+	}{
+		{name: "exception date", calendar: calendar, day: time.Date(2026, time.June, 15, 7, 0, 0, 0, time.UTC), want: true},
+		{name: "other day", calendar: calendar, day: time.Date(2026, time.June, 16, 7, 0, 0, 0, time.UTC)},
+		{name: "date in the location of the day", calendar: calendar, day: time.Date(2026, time.June, 15, 23, 30, 0, 0, tokyo), want: true},
+		{name: "nil calendar", calendar: nil, day: time.Date(2026, time.June, 15, 7, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := tt.calendar.Holiday(tt.day); got != tt.want {
+				t.Errorf("Holiday(%v) = %v, want %v", tt.day, got, tt.want)
+			}
+		})
+	}
+}
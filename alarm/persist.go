@@ -0,0 +1,89 @@
+package alarm
+
+import (
+	"time"
+	"wecker/state"
+)
+
+// persistActive writes the active alarms to the state file so they survive a restart
+// (internal, assumes mutex is held)
+func (m *Manager) persistActive() {
+	records := make([]state.AlarmRecord, 0, len(m.activeAlarms))
+	for alarmID, activeAlarm := range m.activeAlarms {
+		records = append(records, state.AlarmRecord{
+			ID:          alarmID,
+			Alarm:       *activeAlarm.Alarm,
+			State:       int(activeAlarm.State),
+			StartTime:   activeAlarm.StartTime,
+			RingStart:   activeAlarm.RingStart,
+			SnoozeUntil: activeAlarm.SnoozeUntil,
+			SnoozeCount: activeAlarm.SnoozeCount,
+			AutoSnoozes: activeAlarm.AutoSnoozes,
+			Escalated:   activeAlarm.Escalated,
+			Stage:       activeAlarm.Stage,
+			StageStart:  activeAlarm.StageStart,
+			Deadline:    activeAlarm.Deadline,
+		})
+	}
+
+	m.state.SetActiveAlarms(records)
+	go m.state.Save()
+}
+
+// restoreActive brings back the alarms that were ringing, snoozed or in their wake window
+// when wecker stopped. A snoozed alarm rings once its snooze time is reached, even if that
+// happened while wecker wasn't running; alarms whose ring time ran out meanwhile are dropped.
+func (m *Manager) restoreActive(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, record := range m.state.GetActiveAlarms() {
+		// Alarms deleted in the meantime stay silent
+		if m.findAlarm(record.ID) == nil {
+			continue
+		}
+
+		alarm := record.Alarm
+		alarm.ResolveTimezone()
+		activeAlarm := &ActiveAlarm{
+			Alarm:       &alarm,
+			State:       AlarmState(record.State),
+			StartTime:   record.StartTime,
+			RingStart:   record.RingStart,
+			SnoozeUntil: record.SnoozeUntil,
+			SnoozeCount: record.SnoozeCount,
+			AutoSnoozes: record.AutoSnoozes,
+			Escalated:   record.Escalated,
+			Stage:       record.Stage,
+			StageStart:  record.StageStart,
+			Deadline:    record.Deadline,
+		}
+
+		switch activeAlarm.State {
+		case StateTriggered:
+			if now.Sub(activeAlarm.RingStart) >= alarm.RingDuration() {
+				continue
+			}
+			m.activeAlarms[record.ID] = activeAlarm
+			if m.callbacks.OnAlarmTriggered != nil {
+				go m.callbacks.OnAlarmTriggered(record.ID, activeAlarm.Alarm)
+			}
+		case StatePreAlarm:
+			if now.Sub(activeAlarm.Deadline) >= alarm.RingDuration() {
+				continue
+			}
+			m.activeAlarms[record.ID] = activeAlarm
+			if m.callbacks.OnPreAlarmStarted != nil {
+				go m.callbacks.OnPreAlarmStarted(record.ID, activeAlarm.Alarm, activeAlarm.Deadline)
+			}
+		case StateSnoozed:
+			if now.Sub(activeAlarm.SnoozeUntil) >= alarm.RingDuration() {
+				continue
+			}
+			// updateActiveAlarms rings it again once the snooze time is reached
+			m.activeAlarms[record.ID] = activeAlarm
+		}
+	}
+
+	m.persistActive()
+}
@@ -0,0 +1,634 @@
+package alarm
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds how far ahead a recurrence is searched for its next occurrence.
+// Eight years cover yearly rules on February 29 across a skipped leap year.
+const maxSearchDays = 8 * 366
+
+// Recurrence is a parsed repeat expression of an alarm: either a subset of the
+// iCalendar RRULE syntax (RFC 5545) or a 5-field cron expression
+type Recurrence interface {
+	// next returns the first occurrence strictly after the given time, or the zero time.
+	// Day based rules ring at the alarm clock time; anchor is the first day of the
+	// series (used for INTERVAL) and may be zero.
+	next(after time.Time, at clockTime, anchor time.Time) time.Time
+
+	// UsesAlarmTime reports whether occurrences ring at the alarm time (RRULE) or at
+	// times of their own (cron)
+	UsesAlarmTime() bool
+
+	// NeedsStartDate reports whether the days the rule rings on follow from the start
+	// date of the series, like a bare FREQ=WEEKLY or an INTERVAL above 1
+	NeedsStartDate() bool
+}
+
+// clockTime is a time of day
+type clockTime struct {
+	hour, minute, second int
+}
+
+// dstProbe is how far before a time the previous UTC offset is looked up. It exceeds
+// the largest DST shift in use (2h) and is far shorter than the time between changes.
+const dstProbe = 3 * time.Hour
+
+// on returns the clock time on the given day in the day's location. time.Date doesn't
+// define which instant it picks around DST changes, so they are resolved explicitly:
+// a time skipped when clocks spring forward rings once at the shifted time (02:30
+// becomes 03:30), a time that occurs twice when clocks fall back rings at its first instance.
+func (c clockTime) on(day time.Time) time.Time {
+	t := time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, c.second, 0, day.Location())
+	wanted := time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, c.second, 0, time.UTC)
+
+	// Spring forward: the clock time doesn't exist, move past the gap if time.Date went back
+	if shift := wanted.Sub(wallClock(t)); shift != 0 {
+		return t.Add(max(0, shift))
+	}
+
+	// Fall back: an earlier instant shows the same clock time under the previous, larger offset
+	_, offset := t.Zone()
+	if _, previous := t.Add(-dstProbe).Zone(); previous > offset {
+		if earlier := t.Add(-time.Duration(previous-offset) * time.Second); wallClock(earlier).Equal(wanted) {
+			return earlier
+		}
+	}
+	return t
+}
+
+// wallClock returns the clock reading of t as a UTC time, for comparing clock times across offsets
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// ParseRecurrence parses an RRULE ("FREQ=MONTHLY;BYDAY=-1FR", optionally prefixed with
+// "RRULE:") or a 5-field cron expression ("0 7 * * 1-5")
+func ParseRecurrence(expr string) (Recurrence, error) {
+	expr = strings.TrimSpace(expr)
+	upper := strings.ToUpper(expr)
+
+	switch {
+	case expr == "":
+		return nil, fmt.Errorf("empty recurrence")
+	case strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ="):
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	case len(strings.Fields(expr)) == 5:
+		return parseCron(upper)
+	default:
+		return nil, fmt.Errorf("expected an RRULE (FREQ=...) or a 5-field cron expression")
+	}
+}
+
+// startOfDay returns midnight of the given day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	// Calendar dates in UTC avoid DST days of 23 or 25 hours
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// ----------------------------------------------------------------------------
+// RRULE
+
+// rruleFrequencies are the supported FREQ values
+var rruleFrequencies = map[string]bool{"DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true}
+
+// rruleWeekdays maps RRULE day codes to weekdays
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// defaultAnchor is used when the alarm has no start date, only for rules that don't
+// depend on it (a Monday)
+var defaultAnchor = time.Date(1970, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+// weekdayNum is a BYDAY entry like "MO" (n = 0), "1MO" or "-1FR"
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+// rrule is a parsed RRULE subset: FREQ, INTERVAL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS, UNTIL
+type rrule struct {
+	freq       string
+	interval   int
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+	until      time.Time // Date only, zero if unbounded
+}
+
+// parseRRule parses the key=value parts of an RRULE
+func parseRRule(rule string) (*rrule, error) {
+	r := &rrule{interval: 1}
+
+	for _, part := range strings.Split(rule, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part %q, expected KEY=VALUE", part)
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			if !rruleFrequencies[value] {
+				return nil, fmt.Errorf("unsupported FREQ %q, use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+			r.freq = value
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number")
+			}
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(item)
+				if err != nil {
+					return nil, err
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			if r.byMonthDay, err = parseIntList(value, -31, 31); err != nil {
+				return nil, fmt.Errorf("BYMONTHDAY: %v", err)
+			}
+		case "BYMONTH":
+			months, err := parseIntList(value, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("BYMONTH: %v", err)
+			}
+			for _, month := range months {
+				r.byMonth = append(r.byMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			if r.bySetPos, err = parseIntList(value, -366, 366); err != nil {
+				return nil, fmt.Errorf("BYSETPOS: %v", err)
+			}
+		case "UNTIL":
+			if len(value) < 8 {
+				return nil, fmt.Errorf("UNTIL must start with a YYYYMMDD date")
+			}
+			if r.until, err = time.Parse("20060102", value[:8]); err != nil {
+				return nil, fmt.Errorf("UNTIL must start with a YYYYMMDD date")
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("only WKST=MO is supported")
+			}
+		case "COUNT":
+			return nil, fmt.Errorf("COUNT is not supported, use UNTIL or an end date")
+		case "BYHOUR", "BYMINUTE", "BYSECOND":
+			return nil, fmt.Errorf("%s is not supported, the alarm time sets the time of day", key)
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	for _, wd := range r.byDay {
+		if wd.n != 0 && r.freq != "MONTHLY" && r.freq != "YEARLY" {
+			return nil, fmt.Errorf("numbered BYDAY like 1MO needs FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+	if len(r.byMonthDay) > 0 && r.freq == "WEEKLY" {
+		return nil, fmt.Errorf("BYMONTHDAY can't be used with FREQ=WEEKLY")
+	}
+	for _, pos := range r.bySetPos {
+		if pos == 0 {
+			return nil, fmt.Errorf("BYSETPOS can't be 0")
+		}
+	}
+
+	return r, nil
+}
+
+// parseWeekdayNum parses a BYDAY entry like "MO", "2TU" or "-1FR"
+func parseWeekdayNum(s string) (weekdayNum, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY entry %q", s)
+	}
+
+	day, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY weekday in %q, use SU, MO, TU, WE, TH, FR or SA", s)
+	}
+
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return weekdayNum{}, fmt.Errorf("invalid BYDAY position in %q", s)
+		}
+	}
+
+	return weekdayNum{n: n, day: day}, nil
+}
+
+// parseIntList parses a comma separated list of non-zero numbers within [min, max]
+func parseIntList(s string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// UsesAlarmTime implements Recurrence
+func (r *rrule) UsesAlarmTime() bool {
+	return true
+}
+
+// NeedsStartDate implements Recurrence. Without BYDAY or BYMONTHDAY a series repeats on
+// the weekday or day of the month of its start.
+func (r *rrule) NeedsStartDate() bool {
+	return r.interval > 1 || (r.freq != "DAILY" && len(r.byDay) == 0 && len(r.byMonthDay) == 0)
+}
+
+// next implements Recurrence; rules that need a start date never ring without one
+func (r *rrule) next(after time.Time, at clockTime, anchor time.Time) time.Time {
+	loc := after.Location()
+	if anchor.IsZero() {
+		if r.NeedsStartDate() {
+			return time.Time{}
+		}
+		anchor = time.Date(defaultAnchor.Year(), defaultAnchor.Month(), defaultAnchor.Day(), 0, 0, 0, 0, loc)
+	}
+
+	first := startOfDay(after)
+	if anchor.After(first) {
+		first = startOfDay(anchor)
+	}
+
+	for i := 0; i < maxSearchDays; i++ {
+		day := time.Date(first.Year(), first.Month(), first.Day()+i, 0, 0, 0, 0, loc)
+		if !r.until.IsZero() && daysBetween(r.until, day) > 0 {
+			break
+		}
+		if !r.matches(day, anchor) {
+			continue
+		}
+		if candidate := at.on(day); candidate.After(after) {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// matches reports whether the rule rings on the given day
+func (r *rrule) matches(day, anchor time.Time) bool {
+	if !r.inInterval(day, anchor) {
+		return false
+	}
+	if len(r.bySetPos) == 0 {
+		return r.matchesDay(day, anchor)
+	}
+
+	// BYSETPOS picks positions from all matching days of the period
+	var candidates []time.Time
+	for _, d := range r.periodDays(day) {
+		if r.matchesDay(d, anchor) {
+			candidates = append(candidates, d)
+		}
+	}
+	for _, pos := range r.bySetPos {
+		index := pos - 1
+		if pos < 0 {
+			index = len(candidates) + pos
+		}
+		if index >= 0 && index < len(candidates) && daysBetween(candidates[index], day) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// inInterval reports whether the day lies in a period selected by INTERVAL
+func (r *rrule) inInterval(day, anchor time.Time) bool {
+	if daysBetween(anchor, day) < 0 {
+		return false
+	}
+
+	var periods int
+	switch r.freq {
+	case "DAILY":
+		periods = daysBetween(anchor, day)
+	case "WEEKLY":
+		periods = daysBetween(weekStart(anchor), weekStart(day)) / 7
+	case "MONTHLY":
+		periods = (day.Year()-anchor.Year())*12 + int(day.Month()) - int(anchor.Month())
+	case "YEARLY":
+		periods = day.Year() - anchor.Year()
+	}
+	return periods%r.interval == 0
+}
+
+// weekStart returns the Monday of the week containing the day (WKST=MO)
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, day.Location())
+}
+
+// periodDays returns all days of the FREQ period containing the day
+func (r *rrule) periodDays(day time.Time) []time.Time {
+	var start time.Time
+	var length int
+
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{day}
+	case "WEEKLY":
+		start, length = weekStart(day), 7
+	case "MONTHLY":
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		length = daysIn(day.Year(), day.Month())
+	case "YEARLY":
+		start = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+		length = daysBetween(start, start.AddDate(1, 0, 0))
+	}
+
+	days := make([]time.Time, length)
+	for i := range days {
+		days[i] = time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, day.Location())
+	}
+	return days
+}
+
+// daysIn returns the number of days in the month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// matchesDay applies the BYxxx filters (except BYSETPOS) to a day
+func (r *rrule) matchesDay(day, anchor time.Time) bool {
+	if len(r.byMonth) > 0 {
+		found := false
+		for _, month := range r.byMonth {
+			found = found || day.Month() == month
+		}
+		if !found {
+			return false
+		}
+	} else if r.freq == "YEARLY" && len(r.byDay) == 0 && len(r.byMonthDay) == 0 && day.Month() != anchor.Month() {
+		return false
+	}
+
+	if len(r.byMonthDay) > 0 && !r.matchesMonthDay(day) {
+		return false
+	}
+
+	if len(r.byDay) > 0 {
+		return r.matchesByDay(day)
+	}
+
+	// Without BYDAY/BYMONTHDAY the series repeats on the weekday or day of its start
+	switch r.freq {
+	case "WEEKLY":
+		return day.Weekday() == anchor.Weekday()
+	case "MONTHLY", "YEARLY":
+		if len(r.byMonthDay) == 0 {
+			return day.Day() == anchor.Day()
+		}
+	}
+	return true
+}
+
+// matchesMonthDay checks BYMONTHDAY, where negative values count from the month end
+func (r *rrule) matchesMonthDay(day time.Time) bool {
+	last := daysIn(day.Year(), day.Month())
+	for _, md := range r.byMonthDay {
+		if md == day.Day() || (md < 0 && last+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesByDay checks BYDAY; numbered entries count within the month, or within
+// the year for FREQ=YEARLY without BYMONTH
+func (r *rrule) matchesByDay(day time.Time) bool {
+	for _, wd := range r.byDay {
+		if day.Weekday() != wd.day {
+			continue
+		}
+		if wd.n == 0 {
+			return true
+		}
+
+		var index, count int
+		if r.freq == "YEARLY" && len(r.byMonth) == 0 {
+			index = (day.YearDay() - 1) / 7
+			count = (day.YearDay()-1)/7 + (daysBetween(day, time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, day.Location())))/7 + 1
+		} else {
+			index = (day.Day() - 1) / 7
+			count = index + (daysIn(day.Year(), day.Month())-day.Day())/7 + 1
+		}
+
+		if (wd.n > 0 && index == wd.n-1) || (wd.n < 0 && count+wd.n == index) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Cron
+
+// cronMonths and cronWeekdays are the names accepted in cron fields
+var (
+	cronMonths   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// cron is a parsed 5-field cron expression stored as bit sets
+type cron struct {
+	minutes, hours, monthDays, months, weekdays uint64
+	monthDayAny, weekdayAny                     bool
+}
+
+// parseCron parses "minute hour day-of-month month day-of-week"
+func parseCron(expr string) (*cron, error) {
+	fields := strings.Fields(expr)
+	c := &cron{}
+
+	var err error
+	if c.minutes, _, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %v", err)
+	}
+	if c.hours, _, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %v", err)
+	}
+	if c.monthDays, c.monthDayAny, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %v", err)
+	}
+	if c.months, _, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, fmt.Errorf("month: %v", err)
+	}
+	if c.weekdays, c.weekdayAny, err = parseCronField(fields[4], 0, 7, cronWeekdays); err != nil {
+		return nil, fmt.Errorf("day of week: %v", err)
+	}
+
+	// 7 is an alias for Sunday
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+
+	return c, nil
+}
+
+// parseCronField parses a comma list of "*", "N", "A-B" with an optional "/STEP".
+// names, if given, are accepted instead of numbers starting at min. Like Vixie cron,
+// a field starting with "*" (also "*/2") counts as unrestricted for the day rule.
+func parseCronField(field string, min, max int, names []string) (uint64, bool, error) {
+	var set uint64
+	any := strings.HasPrefix(field, "*")
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseCronValue(loPart, min, max, names); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseCronValue(hiPart, min, max, names); err != nil {
+					return 0, false, err
+				}
+			} else if hasStep {
+				hi = max
+			}
+			if hi < lo {
+				return 0, false, fmt.Errorf("invalid range %q", rangePart)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, any, nil
+}
+
+// parseCronValue parses a number or name of a cron field
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if s == name {
+			return min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("value %q out of range %d-%d", s, min, max)
+	}
+	return v, nil
+}
+
+// UsesAlarmTime implements Recurrence
+func (c *cron) UsesAlarmTime() bool {
+	return false
+}
+
+// NeedsStartDate implements Recurrence
+func (c *cron) NeedsStartDate() bool {
+	return false
+}
+
+// next implements Recurrence; the alarm time is ignored since cron carries its own
+func (c *cron) next(after time.Time, _ clockTime, anchor time.Time) time.Time {
+	loc := after.Location()
+	first := startOfDay(after)
+	if !anchor.IsZero() && anchor.After(first) {
+		first = startOfDay(anchor)
+	}
+
+	hours := setBits(c.hours)
+	minutes := setBits(c.minutes)
+
+	for i := 0; i < maxSearchDays; i++ {
+		day := time.Date(first.Year(), first.Month(), first.Day()+i, 0, 0, 0, 0, loc)
+		if !c.matchesDay(day) {
+			continue
+		}
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				candidate := clockTime{hour: hour, minute: minute}.on(day)
+				if candidate.After(after) {
+					return candidate
+				}
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay applies the cron rule that a restricted day of month and day of week
+// are combined with OR
+func (c *cron) matchesDay(day time.Time) bool {
+	if c.months&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+
+	monthDay := c.monthDays&(1<<uint(day.Day())) != 0
+	weekday := c.weekdays&(1<<uint(day.Weekday())) != 0
+
+	switch {
+	case c.monthDayAny && c.weekdayAny:
+		return true
+	case c.monthDayAny:
+		return weekday
+	case c.weekdayAny:
+		return monthDay
+	default:
+		return monthDay || weekday
+	}
+}
+
+// setBits returns the positions of the set bits in ascending order
+func setBits(set uint64) []int {
+	var result []int
+	for set != 0 {
+		bit := bits.TrailingZeros64(set)
+		result = append(result, bit)
+		set &^= 1 << uint(bit)
+	}
+	return result
+}
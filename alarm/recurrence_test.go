@@ -0,0 +1,192 @@
+package alarm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "unsupported FREQ", expr: "FREQ=HOURLY"},
+		{name: "missing FREQ", expr: "RRULE:BYDAY=MO"},
+		{name: "BYMONTHDAY above 31", expr: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{name: "BYMONTHDAY below -31", expr: "FREQ=MONTHLY;BYMONTHDAY=-32"},
+		{name: "BYMONTHDAY zero", expr: "FREQ=MONTHLY;BYMONTHDAY=0"},
+		{name: "numbered BYDAY with WEEKLY", expr: "FREQ=WEEKLY;BYDAY=1MO"},
+		{name: "BYSETPOS zero", expr: "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=0"},
+		{name: "cron with 4 fields", expr: "0 7 * *"},
+		{name: "cron with 6 fields", expr: "0 7 * * * *"},
+		{name: "cron minute out of range", expr: "60 7 * * *"},
+		{name: "cron weekday out of range", expr: "0 7 * * 8"},
+		{name: "cron reversed range", expr: "0 7 * * 5-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRecurrence(tt.expr); err == nil {
+				t.Errorf("ParseRecurrence(%q) succeeded, want an error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// January 1, 2026 is a Thursday; UTC keeps DST out of these tests
+	date := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	at := clockTime{hour: 7}
+
+	tests := []struct {
+		name   string
+		expr   string
+		after  time.Time
+		anchor time.Time
+		want   time.Time // Zero if the rule doesn't ring again
+	}{
+		{
+			name:  "last weekday of the month with BYSETPOS=-1",
+			expr:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 30, 7, 0),
+		},
+		{
+			name:  "BYSETPOS=-1 in a month ending on a Saturday",
+			expr:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			after: date(2026, time.January, 30, 8, 0),
+			want:  date(2026, time.February, 27, 7, 0),
+		},
+		{
+			name:  "last Friday with -1FR",
+			expr:  "FREQ=MONTHLY;BYDAY=-1FR",
+			after: date(2026, time.January, 10, 8, 0),
+			want:  date(2026, time.January, 30, 7, 0),
+		},
+		{
+			name:  "second Tuesday with 2TU",
+			expr:  "FREQ=MONTHLY;BYDAY=2TU",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 13, 7, 0),
+		},
+		{
+			name:   "every other week from the start date",
+			expr:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			after:  date(2026, time.January, 5, 8, 0),
+			anchor: date(2026, time.January, 5, 0, 0),
+			want:   date(2026, time.January, 19, 7, 0),
+		},
+		{
+			name:   "every other week from a later start date",
+			expr:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			after:  date(2026, time.January, 5, 8, 0),
+			anchor: date(2026, time.January, 12, 0, 0),
+			want:   date(2026, time.January, 12, 7, 0),
+		},
+		{
+			name:   "every other day",
+			expr:   "FREQ=DAILY;INTERVAL=2",
+			after:  date(2026, time.January, 1, 8, 0),
+			anchor: date(2026, time.January, 1, 0, 0),
+			want:   date(2026, time.January, 3, 7, 0),
+		},
+		{
+			name:  "INTERVAL=2 without a start date never rings",
+			expr:  "FREQ=DAILY;INTERVAL=2",
+			after: date(2026, time.January, 1, 8, 0),
+		},
+		{
+			name:  "UNTIL includes its day",
+			expr:  "FREQ=DAILY;UNTIL=20260103",
+			after: date(2026, time.January, 2, 8, 0),
+			want:  date(2026, time.January, 3, 7, 0),
+		},
+		{
+			name:  "UNTIL ends the series",
+			expr:  "FREQ=DAILY;UNTIL=20260103T000000Z",
+			after: date(2026, time.January, 3, 8, 0),
+		},
+		{
+			name:  "February 29 waits for a leap year",
+			expr:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2028, time.February, 29, 7, 0),
+		},
+		{
+			name:  "BYMONTHDAY=31 skips shorter months",
+			expr:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			after: date(2026, time.January, 31, 8, 0),
+			want:  date(2026, time.March, 31, 7, 0),
+		},
+		{
+			name:  "BYMONTHDAY=-1 is the last day",
+			expr:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after: date(2026, time.January, 31, 8, 0),
+			want:  date(2026, time.February, 28, 7, 0),
+		},
+		{
+			name:  "cron weekdays",
+			expr:  "0 7 * * 1-5",
+			after: date(2026, time.January, 2, 8, 0),
+			want:  date(2026, time.January, 5, 7, 0),
+		},
+		{
+			name:  "cron carries its own time",
+			expr:  "30 6,8 * * *",
+			after: date(2026, time.January, 1, 7, 0),
+			want:  date(2026, time.January, 1, 8, 30),
+		},
+		{
+			name:  "cron day of month or weekday, weekday first",
+			expr:  "0 7 13 * FRI",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 2, 7, 0),
+		},
+		{
+			name:  "cron day of month or weekday, day of month first",
+			expr:  "0 7 13 * FRI",
+			after: date(2026, time.January, 10, 8, 0),
+			want:  date(2026, time.January, 13, 7, 0),
+		},
+		{
+			name:  "cron Sunday written as 7",
+			expr:  "0 7 * * 7",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 4, 7, 0),
+		},
+		{
+			name:  "cron stepped day of month counts as unrestricted",
+			expr:  "0 7 */1 * 1",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 5, 7, 0),
+		},
+		{
+			name:  "cron stepped weekday counts as unrestricted",
+			expr:  "0 7 20 * */1",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.January, 20, 7, 0),
+		},
+		{
+			name:  "cron month restriction",
+			expr:  "0 7 1 MAR *",
+			after: date(2026, time.January, 1, 8, 0),
+			want:  date(2026, time.March, 1, 7, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q): %v", tt.expr, err)
+			}
+			got := r.next(tt.after, at, tt.anchor)
+			if !got.Equal(tt.want) {
+				t.Errorf("next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
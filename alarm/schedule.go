@@ -0,0 +1,165 @@
+package alarm
+
+import (
+	"time"
+	"wecker/config"
+)
+
+// NextOccurrence returns the first time strictly after the given time at which the
+// alarm is due, or the zero time if it never rings again. The enabled flag is ignored
+// so the result can also be used to preview disabled alarms. Alarm times and dates are
+// evaluated in the alarm's timezone, the result is in the location of after.
+func NextOccurrence(a *config.Alarm, after time.Time) time.Time {
+	next := nextOccurrenceIn(a, after.In(a.Location()))
+	if next.IsZero() {
+		return next
+	}
+	return next.In(after.Location())
+}
+
+// nextOccurrenceIn implements NextOccurrence in the location of after
+func nextOccurrenceIn(a *config.Alarm, after time.Time) time.Time {
+	hour, minute, second, err := a.ClockTime()
+	if err != nil {
+		return time.Time{}
+	}
+
+	loc := after.Location()
+	clock := clockTime{hour: hour, minute: minute, second: second}
+	at := clock.on
+
+	// One-shot alarms ring on their date only
+	if a.IsOneShot() {
+		day, err := config.ParseDate(a.Date, loc)
+		if err != nil {
+			return time.Time{}
+		}
+		if candidate := at(day); candidate.After(after) {
+			return candidate
+		}
+		return time.Time{}
+	}
+
+	// Repeating alarms, optionally limited to a date range
+	var startDate, endDate time.Time
+	if a.StartDate != "" {
+		if startDate, err = config.ParseDate(a.StartDate, loc); err != nil {
+			return time.Time{}
+		}
+	}
+	if a.EndDate != "" {
+		if endDate, err = config.ParseDate(a.EndDate, loc); err != nil {
+			return time.Time{}
+		}
+	}
+
+	// A recurrence expression replaces the weekday selection
+	if a.Recurrence != "" {
+		recurrence, err := ParseRecurrence(a.Recurrence)
+		if err != nil {
+			return time.Time{}
+		}
+		next := recurrence.next(after, clock, startDate)
+		if next.IsZero() || (!endDate.IsZero() && daysBetween(endDate, next) > 0) {
+			return time.Time{}
+		}
+		return next
+	}
+
+	if len(a.Days) != 7 {
+		return time.Time{}
+	}
+
+	first := after
+	if startDate.After(first) {
+		first = startDate
+	}
+
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(first.Year(), first.Month(), first.Day()+offset, 0, 0, 0, 0, loc)
+		if !endDate.IsZero() && day.After(endDate) {
+			break
+		}
+		if candidate := at(day); candidate.After(after) && a.Days[day.Weekday()] {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// clockJumped reports whether the wall clock moved differently from the monotonic
+// clock between two readings, which happens when the system time is changed or the
+// machine resumes from suspend
+func clockJumped(prev, now time.Time) bool {
+	monotonic := now.Sub(prev)
+	wall := now.Round(0).Sub(prev.Round(0))
+	drift := wall - monotonic
+	return drift > clockJumpThreshold || drift < -clockJumpThreshold
+}
+
+// NextOccurrences returns up to n upcoming occurrences of the alarm after the given time
+func NextOccurrences(a *config.Alarm, after time.Time, n int) []time.Time {
+	var result []time.Time
+	for len(result) < n {
+		next := NextOccurrence(a, after)
+		if next.IsZero() {
+			break
+		}
+		result = append(result, next)
+		after = next
+	}
+	return result
+}
+
+// SkipReason explains why an occurrence of an enabled alarm doesn't ring
+type SkipReason string
+
+const (
+	SkipHoliday SkipReason = "holiday"
+	SkipOnce    SkipReason = "skip" // Skipped on request via SkipUntil
+)
+
+// SkippedOccurrence is an occurrence the scheduler passed over
+type SkippedOccurrence struct {
+	Occurrence time.Time
+	Reason     SkipReason
+	Holiday    string // Holiday name for SkipHoliday
+}
+
+// skipOccurrence reports why an occurrence must not ring, or nil if it should ring
+func (m *Manager) skipOccurrence(a *config.Alarm, occurrence time.Time) *SkippedOccurrence {
+	if !occurrence.After(a.SkipUntil) {
+		return &SkippedOccurrence{Occurrence: occurrence, Reason: SkipOnce}
+	}
+	if !a.RespectHolidays {
+		return nil
+	}
+	// Holidays are dates in the alarm's own timezone
+	if name, isHoliday := m.holidays.Holiday(occurrence.In(a.Location())); isHoliday {
+		return &SkippedOccurrence{Occurrence: occurrence, Reason: SkipHoliday, Holiday: name}
+	}
+	return nil
+}
+
+// planNext returns the next occurrence that should ring, passing over skipped occurrences
+// and holidays for holiday-aware alarms. The first occurrence passed over is returned as skipped.
+// After maxHolidaySkips occurrences in a row the last one checked is returned, so the
+// scheduler plans on from there once it is reached; skipOccurrence tells it apart.
+func (m *Manager) planNext(a *config.Alarm, after time.Time) (time.Time, *SkippedOccurrence) {
+	var skipped *SkippedOccurrence
+
+	next := NextOccurrence(a, after)
+	for i := 0; !next.IsZero(); i++ {
+		skip := m.skipOccurrence(a, next)
+		if skip == nil || i == maxHolidaySkips {
+			break
+		}
+		if skipped == nil {
+			skipped = skip
+		}
+		next = NextOccurrence(a, next)
+	}
+
+	return next, skipped
+}
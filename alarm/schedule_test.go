@@ -0,0 +1,227 @@
+package alarm
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // The tests don't depend on the system zoneinfo
+	"wecker/config"
+)
+
+// mustLoad loads a timezone or fails the test
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+// dailyAlarm returns an enabled alarm ringing every day at the given time
+func dailyAlarm(alarmTime, timezone string) config.Alarm {
+	a := config.DefaultAlarm(1, alarmTime)
+	a.Enabled = true
+	a.Days = []bool{true, true, true, true, true, true, true}
+	if err := a.SetTimezone(timezone); err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func TestClockTimeOn(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	berlin := mustLoad(t, "Europe/Berlin")
+
+	tests := []struct {
+		name  string
+		day   time.Time
+		clock clockTime
+		want  time.Time // In UTC
+	}{
+		{
+			name:  "regular day",
+			day:   time.Date(2026, time.March, 7, 0, 0, 0, 0, newYork),
+			clock: clockTime{hour: 7},
+			want:  time.Date(2026, time.March, 7, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "spring forward gap moves past the gap",
+			day:   time.Date(2026, time.March, 8, 0, 0, 0, 0, newYork),
+			clock: clockTime{hour: 2, minute: 30},
+			want:  time.Date(2026, time.March, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+		},
+		{
+			name:  "after the spring forward gap",
+			day:   time.Date(2026, time.March, 8, 0, 0, 0, 0, newYork),
+			clock: clockTime{hour: 3, minute: 30},
+			want:  time.Date(2026, time.March, 8, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "fall back repeat picks the first instance",
+			day:   time.Date(2026, time.November, 1, 0, 0, 0, 0, newYork),
+			clock: clockTime{hour: 1, minute: 30},
+			want:  time.Date(2026, time.November, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+		},
+		{
+			name:  "after the fall back repeat",
+			day:   time.Date(2026, time.November, 1, 0, 0, 0, 0, newYork),
+			clock: clockTime{hour: 3},
+			want:  time.Date(2026, time.November, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "spring forward gap in Europe",
+			day:   time.Date(2026, time.March, 29, 0, 0, 0, 0, berlin),
+			clock: clockTime{hour: 2, minute: 30},
+			want:  time.Date(2026, time.March, 29, 1, 30, 0, 0, time.UTC), // 03:30 CEST
+		},
+		{
+			name:  "fall back repeat in Europe",
+			day:   time.Date(2026, time.October, 25, 0, 0, 0, 0, berlin),
+			clock: clockTime{hour: 2, minute: 30},
+			want:  time.Date(2026, time.October, 25, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.clock.on(tt.day); !got.Equal(tt.want) {
+				t.Errorf("on() = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name  string
+		alarm config.Alarm
+		after time.Time
+		want  []time.Time // In UTC
+	}{
+		{
+			name:  "spring forward gap rings once at the shifted time",
+			alarm: dailyAlarm("02:30:00", "America/New_York"),
+			after: time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.March, 8, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+				time.Date(2026, time.March, 9, 6, 30, 0, 0, time.UTC), // 02:30 EDT
+			},
+		},
+		{
+			name:  "fall back repeat rings once on the first instance",
+			alarm: dailyAlarm("01:30:00", "America/New_York"),
+			after: time.Date(2026, time.October, 31, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.November, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+				time.Date(2026, time.November, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST
+			},
+		},
+		{
+			name:  "timezone other than the local one",
+			alarm: dailyAlarm("07:00:00", "Asia/Tokyo"),
+			after: time.Date(2026, time.October, 16, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.October, 16, 22, 0, 0, 0, time.UTC), // 07:00 JST
+				time.Date(2026, time.October, 17, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "timezone across the local DST change",
+			alarm: dailyAlarm("07:00:00", "Asia/Tokyo"),
+			after: time.Date(2026, time.October, 31, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.October, 31, 22, 0, 0, 0, time.UTC), // 18:00 EDT
+				time.Date(2026, time.November, 1, 22, 0, 0, 0, time.UTC), // 17:00 EST
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextOccurrences(&tt.alarm, tt.after, len(tt.want))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i].UTC(), tt.want[i])
+				}
+				if got[i].Location() != tt.after.Location() {
+					t.Errorf("occurrence %d is in %v, want the location of after %v", i, got[i].Location(), tt.after.Location())
+				}
+			}
+		})
+	}
+}
+
+func TestPlanNextHoliday(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	calendar, err := LoadHolidays([]string{"2026-05-04"}, nil)
+	if err != nil {
+		t.Fatalf("LoadHolidays: %v", err)
+	}
+	m := &Manager{holidays: calendar}
+
+	alarm := dailyAlarm("07:00:00", "Asia/Tokyo")
+	alarm.RespectHolidays = true
+
+	tests := []struct {
+		name        string
+		after       time.Time
+		want        time.Time // In UTC
+		wantSkipped time.Time // Zero if nothing is skipped
+	}{
+		{
+			name:        "holiday in the alarm's timezone is skipped",
+			after:       time.Date(2026, time.May, 3, 12, 0, 0, 0, newYork),  // May 4 01:00 JST
+			want:        time.Date(2026, time.May, 4, 22, 0, 0, 0, time.UTC), // May 5 07:00 JST
+			wantSkipped: time.Date(2026, time.May, 3, 22, 0, 0, 0, time.UTC), // May 4 07:00 JST
+		},
+		{
+			name:  "day after the holiday rings although it is the holiday locally",
+			after: time.Date(2026, time.May, 4, 12, 0, 0, 0, newYork),  // May 5 01:00 JST
+			want:  time.Date(2026, time.May, 4, 22, 0, 0, 0, time.UTC), // May 5 07:00 JST
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped := m.planNext(&alarm, tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", got.UTC(), tt.want)
+			}
+			switch {
+			case tt.wantSkipped.IsZero() && skipped != nil:
+				t.Errorf("skipped %v, want no skipped occurrence", skipped.Occurrence.UTC())
+			case !tt.wantSkipped.IsZero() && (skipped == nil || !skipped.Occurrence.Equal(tt.wantSkipped)):
+				t.Errorf("skipped %+v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestPlanNextManySkips(t *testing.T) {
+	m := &Manager{}
+	alarm := dailyAlarm("07:00:00", "UTC")
+	after := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	alarm.SkipUntil = after.AddDate(2, 0, 0)
+
+	// Each call passes over at most maxHolidaySkips occurrences and returns the last one
+	// checked, planning on from it reaches the first occurrence after SkipUntil
+	next, skipped := m.planNext(&alarm, after)
+	if skipped == nil || !skipped.Occurrence.Equal(time.Date(2026, time.January, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("skipped = %+v, want the first occurrence", skipped)
+	}
+	for calls := 1; m.skipOccurrence(&alarm, next) != nil; calls++ {
+		if next.IsZero() || calls > 3 {
+			t.Fatalf("planning stopped at %v after %d calls", next, calls)
+		}
+		next, _ = m.planNext(&alarm, next)
+	}
+
+	if want := time.Date(2028, time.January, 2, 7, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("next = %v, want %v", next, want)
+	}
+}
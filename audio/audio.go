@@ -1,10 +1,14 @@
 package audio
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +19,11 @@ import (
 // Player manages audio playback
 type Player struct {
 	currentProcess *exec.Cmd
+	processDone    chan struct{}      // Closed once currentProcess has exited
+	cancelTone     context.CancelFunc // Stops the current .tone playback
+	toneGain       *tone.Gain         // Live volume of the current .tone playback
+	overlayCtx     context.Context    // Context of PlayTone overlays, cancelled by Stop
+	cancelOverlays context.CancelFunc
 	mutex          sync.Mutex
 	config         *config.Config
 	isPlaying      bool
@@ -23,13 +32,26 @@ type Player struct {
 	startTime      time.Time
 	buzzerFiles    []string
 	sootherFiles   []string
+	generation     int    // Incremented whenever playback stops, ends ramps of earlier playbacks
+	sleepPlayback  bool   // Current playback belongs to the sleep timer
+	ipcSocket      string // mpv IPC socket of the current process, empty if its volume can't change
+
+	onSleepFinished func() // Called when the sleep audio ends by itself
 }
 
+const (
+	preAlarmStartVolume = 1                      // Volume at the start of a wake window
+	preAlarmRampStep    = 10 * time.Second       // Interval of wake window volume updates
+	mpvIPCTimeout       = time.Second            // Limit for volume commands sent to mpv
+	toneRepeatDelay     = 100 * time.Millisecond // Pause between repeats of a looping .tone file
+)
+
 // NewPlayer creates a new audio player
 func NewPlayer(cfg *config.Config) *Player {
 	p := &Player{
 		config: cfg,
 	}
+	p.overlayCtx, p.cancelOverlays = context.WithCancel(context.Background())
 	p.discoverToneFiles()
 	return p
 }
@@ -64,6 +86,14 @@ func findToneFiles(dir string) []string {
 	return files
 }
 
+// SetOnSleepFinished sets the function called when the sleep audio ends by itself,
+// e.g. at the end of the file when the sleep timer runs until the end
+func (p *Player) SetOnSleepFinished(fn func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.onSleepFinished = fn
+}
+
 // PlayAlarm plays an alarm sound based on the alarm configuration
 func (p *Player) PlayAlarm(alarm *config.Alarm) error {
 	p.mutex.Lock()
@@ -87,16 +117,7 @@ func (p *Player) PlayAlarm(alarm *config.Alarm) error {
 			}
 		}
 
-		p.isPlaying = true
-		p.currentVolume = alarm.Volume
-		p.startTime = time.Now()
-
-		// Play tone file in a goroutine to avoid blocking
-		go func() {
-			tone.PlayToneFile(toneFile)
-		}()
-
-		return nil
+		return p.startTone(toneFile, alarm.Volume, alarm.VolumeRamp, false)
 
 	case config.SourceSoother:
 		// Use ToneParser for soother sounds
@@ -112,16 +133,7 @@ func (p *Player) PlayAlarm(alarm *config.Alarm) error {
 			}
 		}
 
-		p.isPlaying = true
-		p.currentVolume = alarm.Volume
-		p.startTime = time.Now()
-
-		// Play tone file in a goroutine to avoid blocking
-		go func() {
-			tone.PlayToneFile(toneFile)
-		}()
-
-		return nil
+		return p.startTone(toneFile, alarm.Volume, alarm.VolumeRamp, false)
 
 	case config.SourceMP3:
 		// Use PlayerCommand for MP3
@@ -129,7 +141,7 @@ func (p *Player) PlayAlarm(alarm *config.Alarm) error {
 		if audioPath == "" {
 			audioPath = p.config.LastMP3Path
 		}
-		return p.startPlayback(audioPath, alarm.Volume, alarm.VolumeRamp)
+		return p.startPlayback(audioPath, alarm.Volume, alarm.VolumeRamp, true)
 
 	case config.SourceRadio:
 		// Use PlayerCommand for radio
@@ -137,13 +149,101 @@ func (p *Player) PlayAlarm(alarm *config.Alarm) error {
 		if audioPath == "" {
 			audioPath = p.config.LastRadioURL
 		}
-		return p.startPlayback(audioPath, alarm.Volume, alarm.VolumeRamp)
+		return p.startPlayback(audioPath, alarm.Volume, alarm.VolumeRamp, true)
 
 	default:
 		return fmt.Errorf("unknown alarm source: %s", alarm.Source)
 	}
 }
 
+// PlayAlarmStage plays the given stage of an escalating alarm; alarms without
+// stages play their own source
+func (p *Player) PlayAlarmStage(alarm *config.Alarm, stage int) error {
+	stageAlarm := alarm.StageAlarm(stage)
+	return p.PlayAlarm(&stageAlarm)
+}
+
+// PlayPreAlarm plays the quiet pre-alarm of a wake window. The volume rises linearly
+// from almost silent to the alarm volume, which is reached at deadline.
+func (p *Player) PlayPreAlarm(alarm *config.Alarm, deadline time.Time) error {
+	preAlarm := alarm.StageAlarm(0)
+	preAlarm.VolumeRamp = false
+
+	// A player that can't change its volume while playing would stay almost silent
+	if !p.canChangeVolume(preAlarm.Source) {
+		return p.PlayAlarm(&preAlarm)
+	}
+
+	targetVolume := preAlarm.Volume
+	preAlarm.Volume = preAlarmStartVolume
+
+	if err := p.PlayAlarm(&preAlarm); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	generation := p.generation
+	p.mutex.Unlock()
+
+	go p.rampVolumeUntil(generation, preAlarmStartVolume, targetVolume, time.Now(), deadline)
+	return nil
+}
+
+// rampVolumeUntil raises the volume linearly from startVolume at start to targetVolume at
+// deadline. It ends early once the playback it belongs to has stopped.
+func (p *Player) rampVolumeUntil(generation, startVolume, targetVolume int, start, deadline time.Time) {
+	ticker := time.NewTicker(preAlarmRampStep)
+	defer ticker.Stop()
+
+	total := deadline.Sub(start)
+	for range ticker.C {
+		p.mutex.Lock()
+		if p.generation != generation || !p.isPlaying {
+			p.mutex.Unlock()
+			return
+		}
+
+		elapsed := time.Since(start)
+		volume := targetVolume
+		if elapsed < total {
+			volume = startVolume + int(float64(targetVolume-startVolume)*elapsed.Seconds()/total.Seconds())
+		}
+		p.setVolume(volume)
+		p.mutex.Unlock()
+
+		if volume >= targetVolume {
+			return
+		}
+	}
+}
+
+// PlayTone plays a .tone file once on top of the current playback, e.g. when a countdown
+// finishes. An empty path plays the first buzzer tone. Stop silences it.
+func (p *Player) PlayTone(toneFile string) error {
+	p.mutex.Lock()
+	if toneFile == "" {
+		if len(p.buzzerFiles) == 0 {
+			p.mutex.Unlock()
+			return fmt.Errorf("no buzzer .tone files found")
+		}
+		toneFile = p.buzzerFiles[0]
+	}
+	ctx := p.overlayCtx
+	p.mutex.Unlock()
+
+	program, err := tone.ParseFile(toneFile)
+	if err != nil {
+		return err
+	}
+	engine, err := tone.Output()
+	if err != nil {
+		return err
+	}
+
+	go engine.Play(ctx, program, nil)
+	return nil
+}
+
 // PlaySleepAudio plays audio for sleep timer using configured settings
 func (p *Player) PlaySleepAudio() error {
 	p.mutex.Lock()
@@ -169,22 +269,11 @@ func (p *Player) PlaySleepAudio() error {
 			}
 		}
 
-		p.isPlaying = true
-		p.currentVolume = sleepTimer.Volume
-		p.startTime = time.Now()
-
-		// Play tone file continuously in a goroutine for sleep timer
-		go func() {
-			for p.isPlaying {
-				tone.PlayToneFile(toneFile)
-
-				// Small delay before repeating to avoid tight loop
-				if p.isPlaying {
-					time.Sleep(100 * time.Millisecond)
-				}
-			}
-		}()
-
+		// Play tone file continuously for sleep timer, or once when the sleep timer ends with it
+		if err := p.startTone(toneFile, sleepTimer.Volume, false, !sleepTimer.UntilEnd); err != nil {
+			return err
+		}
+		p.sleepPlayback = true
 		return nil
 
 	case config.SourceMP3:
@@ -193,7 +282,8 @@ func (p *Player) PlaySleepAudio() error {
 		if audioPath == "" {
 			audioPath = p.config.LastMP3Path
 		}
-		return p.startPlayback(audioPath, sleepTimer.Volume, false)
+		p.sleepPlayback = true
+		return p.startPlayback(audioPath, sleepTimer.Volume, false, !sleepTimer.UntilEnd)
 
 	case config.SourceRadio:
 		// Use PlayerCommand for radio
@@ -201,34 +291,98 @@ func (p *Player) PlaySleepAudio() error {
 		if audioPath == "" {
 			audioPath = p.config.LastRadioURL
 		}
-		return p.startPlayback(audioPath, sleepTimer.Volume, false)
+		p.sleepPlayback = true
+		return p.startPlayback(audioPath, sleepTimer.Volume, false, !sleepTimer.UntilEnd)
 
 	default:
 		return fmt.Errorf("unknown sleep timer source: %s", sleepTimer.Source)
 	}
 }
 
-// startPlayback starts audio playback with the specified parameters
-func (p *Player) startPlayback(audioPath string, volume int, rampVolume bool) error {
+// startTone plays a .tone file on the tone output until it has finished, or over and
+// over until playback stops when loop is set (internal, assumes mutex is held). The
+// volume of the tones follows setVolume while they play.
+func (p *Player) startTone(toneFile string, volume int, rampVolume, loop bool) error {
+	program, err := tone.ParseFile(toneFile)
+	if err != nil {
+		return err
+	}
+	engine, err := tone.Output()
+	if err != nil {
+		return err
+	}
+
+	startVolume := volume
+	if rampVolume {
+		// Start at half the volume, a quarter of the gain, so a ramp from the default
+		// volume starts audible and reaches the former fixed tone level within minutes
+		startVolume = max(1, volume/2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gain := tone.NewGain(tone.VolumeGain(startVolume))
+	p.cancelTone = cancel
+	p.toneGain = gain
+	p.isPlaying = true
+	p.currentVolume = startVolume
+	p.volumeRamp = rampVolume
+	p.startTime = time.Now()
+
+	generation := p.generation
+	if rampVolume {
+		go p.volumeRampLoop(generation, startVolume, volume)
+	}
+
+	go func() {
+		for engine.Play(ctx, program, gain) == nil && loop {
+			// Small delay before repeating to avoid tight loop
+			select {
+			case <-ctx.Done():
+			case <-time.After(toneRepeatDelay):
+			}
+		}
+		p.playbackEnded(generation)
+	}()
+
+	return nil
+}
+
+// startPlayback starts audio playback with the specified parameters; without loop the
+// playback ends with the file or playlist
+func (p *Player) startPlayback(audioPath string, volume int, rampVolume, loop bool) error {
 	if audioPath == "" {
 		return fmt.Errorf("empty audio path")
 	}
 
+	// A ramp needs volume changes during playback, without them it would stay at its quiet start
+	liveVolume := liveVolumeSupported(p.config.PlayerCommand)
+	if !liveVolume {
+		rampVolume = false
+	}
+
 	// Prepare command arguments
 	args := []string{audioPath}
 
 	// Add volume control if supported
+	startVolume := volume
+	if rampVolume {
+		// Start with lower volume for ramping
+		startVolume = max(1, volume/4)
+	}
 	if volume > 0 && volume <= 100 {
-		volumePercent := volume
-		if rampVolume {
-			// Start with lower volume for ramping
-			volumePercent = max(1, volume/4)
-		}
-		args = append(args, "--volume", fmt.Sprintf("%d", volumePercent))
+		args = append(args, "--volume", fmt.Sprintf("%d", startVolume))
 	}
 
 	// Add loop flag for continuous playback
-	args = append(args, "--loop")
+	if loop {
+		args = append(args, "--loop")
+	}
+
+	// mpv takes volume changes during playback through its IPC socket
+	if liveVolume {
+		p.ipcSocket = filepath.Join(os.TempDir(), fmt.Sprintf("wecker-mpv-%d.sock", os.Getpid()))
+		args = append(args, "--input-ipc-server="+p.ipcSocket)
+	}
 
 	// Create and start process
 	p.currentProcess = exec.Command(p.config.PlayerCommand, args...)
@@ -239,67 +393,187 @@ func (p *Player) startPlayback(audioPath string, volume int, rampVolume bool) er
 
 	err := p.currentProcess.Start()
 	if err != nil {
+		p.currentProcess = nil
 		return fmt.Errorf("failed to start audio player: %v", err)
 	}
+	p.processDone = make(chan struct{})
+	go p.watchProcess(p.currentProcess, p.processDone, p.generation)
 
 	p.isPlaying = true
-	p.currentVolume = volume
+	p.currentVolume = startVolume
 	p.volumeRamp = rampVolume
 	p.startTime = time.Now()
 
 	// Start volume ramping if enabled
 	if rampVolume {
-		go p.volumeRampLoop(volume)
+		go p.volumeRampLoop(p.generation, startVolume, volume)
 	}
 
 	return nil
 }
 
-// volumeRampLoop gradually increases volume over time
-func (p *Player) volumeRampLoop(targetVolume int) {
-	if !p.volumeRamp || targetVolume <= 0 {
+// watchProcess waits for a player process to exit; done is closed as soon as it has
+func (p *Player) watchProcess(cmd *exec.Cmd, done chan struct{}, generation int) {
+	cmd.Wait()
+	close(done)
+	p.playbackEnded(generation)
+}
+
+// playbackEnded marks playback as over when it ended by itself rather than through a
+// stop, and reports the end of the sleep audio
+func (p *Player) playbackEnded(generation int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.generation != generation {
+		return // Stopped or replaced in the meantime
+	}
+
+	sleepPlayback := p.sleepPlayback
+	p.currentProcess = nil
+	p.stopInternal()
+
+	if sleepPlayback && p.onSleepFinished != nil {
+		go p.onSleepFinished()
+	}
+}
+
+// volumeRampLoop gradually increases volume over time from startVolume to targetVolume.
+// It ends early once the playback it belongs to has stopped.
+func (p *Player) volumeRampLoop(generation, startVolume, targetVolume int) {
+	if targetVolume <= 0 {
 		return
 	}
 
 	ticker := time.NewTicker(30 * time.Second) // Increase every 30 seconds
 	defer ticker.Stop()
 
-	startVolume := max(1, targetVolume/4)
 	currentVol := startVolume
 
-	for p.isPlaying && currentVol < targetVolume {
-		select {
-		case <-ticker.C:
-			currentVol = min(targetVolume, currentVol+10)
-			p.setVolume(currentVol)
+	for currentVol < targetVolume {
+		<-ticker.C
+		currentVol = min(targetVolume, currentVol+10)
+
+		p.mutex.Lock()
+		if p.generation != generation || !p.isPlaying || !p.volumeRamp {
+			p.mutex.Unlock()
+			return
 		}
+		p.setVolume(currentVol)
+		p.mutex.Unlock()
 	}
 }
 
-// setVolume adjusts the current playback volume
+// setVolume adjusts the current playback volume (internal, assumes mutex is held).
+// External players other than mpv, and mpv on Windows, keep the volume they were
+// started with, see liveVolumeSupported.
 func (p *Player) setVolume(volume int) {
-	// Note: This is a simplified implementation
-	// In practice, you might need to use a different approach
-	// depending on the player command capabilities
 	p.currentVolume = volume
+
+	if p.currentProcess == nil {
+		if p.toneGain != nil {
+			p.toneGain.Set(tone.VolumeGain(volume))
+		}
+		return
+	}
+	if p.ipcSocket != "" {
+		// mpv may not have opened its socket yet, the next volume update tries again
+		sendMPVCommand(p.ipcSocket, "set_property", "volume", volume)
+	}
 }
 
-// Stop stops the current audio playback
+// FadeSleepAudio lowers the volume of the sleep audio over the last minutes of the
+// sleep timer, reaching silence when remaining is 0. Before the fade, e.g. after the
+// timer was extended, the configured sleep volume applies. Only .tone sounds and mpv
+// fade, other players play at the sleep volume until the timer stops them.
+func (p *Player) FadeSleepAudio(remaining time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isPlaying || !p.sleepPlayback {
+		return
+	}
+
+	sleepTimer := &p.config.SleepTimer
+	volume := sleepTimer.Volume
+	if fade := sleepTimer.Fade(); remaining < fade {
+		volume = int(float64(volume) * max(0, remaining.Seconds()) / fade.Seconds())
+	}
+	if volume != p.currentVolume {
+		p.setVolume(volume)
+	}
+}
+
+// liveVolumeSupported reports whether the player command takes volume changes while it
+// plays, which ramps, wake windows and sleep fades need. Only mpv does, through a unix
+// socket that isn't available on Windows.
+func liveVolumeSupported(command string) bool {
+	return isMPV(command) && runtime.GOOS != "windows"
+}
+
+// canChangeVolume reports whether the volume of a source can change while it plays
+func (p *Player) canChangeVolume(source config.AlarmSource) bool {
+	switch source {
+	case config.SourceBuzzer, config.SourceSoother:
+		return true
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return liveVolumeSupported(p.config.PlayerCommand)
+}
+
+// isMPV reports whether the player command runs mpv
+func isMPV(command string) bool {
+	return strings.TrimSuffix(filepath.Base(command), ".exe") == "mpv"
+}
+
+// sendMPVCommand sends a JSON IPC command to a running mpv
+func sendMPVCommand(socket string, command ...any) error {
+	conn, err := net.DialTimeout("unix", socket, mpvIPCTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mpv: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(mpvIPCTimeout))
+
+	data, err := json.Marshal(map[string]any{"command": command})
+	if err != nil {
+		return fmt.Errorf("failed to encode mpv command: %v", err)
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send mpv command: %v", err)
+	}
+	return nil
+}
+
+// Stop stops the current audio playback and any tones played on top of it
 func (p *Player) Stop() {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	p.stopInternal()
+
+	p.cancelOverlays()
+	p.overlayCtx, p.cancelOverlays = context.WithCancel(context.Background())
 }
 
 // stopInternal stops playback (internal, assumes mutex is held)
 func (p *Player) stopInternal() {
 	if p.currentProcess != nil {
 		p.currentProcess.Process.Kill()
-		p.currentProcess.Wait()
+		<-p.processDone
 		p.currentProcess = nil
 	}
+	if p.cancelTone != nil {
+		p.cancelTone()
+		p.cancelTone = nil
+	}
+	p.toneGain = nil
 	p.isPlaying = false
 	p.volumeRamp = false
+	p.sleepPlayback = false
+	p.ipcSocket = ""
+	p.generation++
 }
 
 // IsPlaying returns whether audio is currently playing
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wecker/tone"
+)
+
+const usage = `usage:
+  wecker                                         run the alarm clock
+  wecker tone render [-rate HZ] IN.tone OUT.wav  render a tone file to a WAV file
+`
+
+// runCommand runs a command line subcommand and returns the exit code
+func runCommand(args []string) int {
+	if len(args) >= 2 && args[0] == "tone" && args[1] == "render" {
+		return renderTone(args[2:])
+	}
+	fmt.Fprint(os.Stderr, usage)
+	return 2
+}
+
+// renderTone renders a .tone file into a 16-bit PCM WAV file
+func renderTone(args []string) int {
+	flags := flag.NewFlagSet("tone render", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	rate := flags.Int("rate", 44100, "sample rate in Hz")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := tone.RenderFile(flags.Arg(0), flags.Arg(1), *rate); err != nil {
+		fmt.Fprintf(os.Stderr, "wecker: %v\n", err)
+		return 1
+	}
+	return 0
+}
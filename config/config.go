@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,24 +21,214 @@ const (
 	SourceRadio   AlarmSource = "radio"
 )
 
+// DateLayout is the format of calendar dates in the configuration
+const DateLayout = "2006-01-02"
+
+// MissedAlarmAction defines what happens with an alarm occurrence that passed while
+// wecker was not running or the machine was suspended
+type MissedAlarmAction string
+
+const (
+	MissedAlarmNotify MissedAlarmAction = "notify" // Show a notice on the main clock
+	MissedAlarmRing   MissedAlarmAction = "ring"   // Ring late
+)
+
+// NoResponseAction defines what happens when an alarm rang for its maximum ring time
+type NoResponseAction string
+
+const (
+	NoResponseStop     NoResponseAction = "stop"     // Stop ringing
+	NoResponseSnooze   NoResponseAction = "snooze"   // Snooze automatically, counts against MaxSnoozes
+	NoResponseEscalate NoResponseAction = "escalate" // Ring once more at full volume, then stop
+)
+
+// DismissChallenge defines the task that has to be solved to stop a ringing alarm
+type DismissChallenge string
+
+const (
+	ChallengeNone     DismissChallenge = ""         // Stop with a single key
+	ChallengeMath     DismissChallenge = "math"     // Solve arithmetic problems
+	ChallengePhrase   DismissChallenge = "phrase"   // Type a random phrase
+	ChallengeSequence DismissChallenge = "sequence" // Press a random arrow key sequence
+)
+
+// DefaultMaxRingMinutes is how long an alarm rings when MaxRingMinutes is not set
+const DefaultMaxRingMinutes = 60
+
 // Alarm represents a single alarm configuration
 type Alarm struct {
-	ID               int         `json:"id"`
-	Enabled          bool        `json:"enabled"`
-	Time             string      `json:"time"` // HH:MM:SS format
-	Days             []bool      `json:"days"` // 7 days, Sunday=0
+	ID                int              `json:"id"`              // Stable identifier, unique among all alarms
+	Label             string           `json:"label,omitempty"` // Optional display name
+	Enabled           bool             `json:"enabled"`
+	Time              string           `json:"time"`                          // HH:MM:SS format
+	Timezone          string           `json:"timezone,omitempty"`            // IANA name like "America/New_York", empty for local time
+	Days              []bool           `json:"days"`                          // 7 days, Sunday=0
+	Date              string           `json:"date,omitempty"`                // YYYY-MM-DD, rings once on this date instead of weekly
+	StartDate         string           `json:"start_date,omitempty"`          // YYYY-MM-DD, first day of the weekly repeat
+	EndDate           string           `json:"end_date,omitempty"`            // YYYY-MM-DD, last day of the weekly repeat
+	Recurrence        string           `json:"recurrence,omitempty"`          // RRULE ("FREQ=MONTHLY;BYDAY=-1FR") or cron ("0 7 * * 1-5"), replaces Days
+	RespectHolidays   bool             `json:"respect_holidays,omitempty"`    // Stay quiet on holidays and exception dates
+	SkipUntil         time.Time        `json:"skip_until,omitzero"`           // Occurrences up to this time are skipped once
+	MaxRingMinutes    int              `json:"max_ring_minutes,omitempty"`    // Ring time before NoResponseAction, 0 = DefaultMaxRingMinutes
+	NoResponseAction  NoResponseAction `json:"no_response_action,omitempty"`  // What happens when nobody reacts, default stop
+	MaxSnoozes        int              `json:"max_snoozes,omitempty"`         // Snoozes allowed before only dismiss works, 0 = unlimited
+	SnoozeMinutes     int              `json:"snooze_minutes,omitempty"`      // Overrides Config.SnoozeMinutes, 0 = use the global value
+	ProgressiveSnooze []int            `json:"progressive_snooze,omitempty"`  // Minutes per snooze, e.g. 10/7/5/3; the last value repeats
+	Stages            []AlarmStage     `json:"stages,omitempty"`              // Escalating sequence, replaces Source/Volume while ringing
+	WakeWindowMinutes int              `json:"wake_window_minutes,omitempty"` // Quiet pre-alarm rising to full volume at Time, 0 = off
+	Challenge         DismissChallenge `json:"challenge,omitempty"`           // Task to solve before the alarm can be stopped
+	ChallengeCount    int              `json:"challenge_count,omitempty"`     // Problems, words or keys of the challenge, 0 = default
+	Source            AlarmSource      `json:"source"`
+	Volume            int              `json:"volume"`             // 1-100
+	AlarmSourceValue  string           `json:"alarm_source_value"` // file path for .tone/.mp3 files or directory/playlist path
+	VolumeRamp        bool             `json:"volume_ramp"`        // Progressive volume increase
+
+	zone *zone // Timezone resolved by ResolveTimezone
+}
+
+// zone is the cached result of loading an alarm's timezone
+type zone struct {
+	name string
+	loc  *time.Location
+	err  error
+}
+
+// AlarmStage is one step of an escalating alarm, e.g. a soother first and a buzzer later
+type AlarmStage struct {
 	Source           AlarmSource `json:"source"`
+	AlarmSourceValue string      `json:"alarm_source_value"` // file path for .tone/.mp3 files or radio URL
 	Volume           int         `json:"volume"`             // 1-100
-	AlarmSourceValue string      `json:"alarm_source_value"` // file path for .tone/.mp3 files or directory/playlist path
-	VolumeRamp       bool        `json:"volume_ramp"`        // Progressive volume increase
+	DurationMinutes  int         `json:"duration_minutes"`   // Time until the next stage, 0 = until the alarm stops
+}
+
+// Pomodoro defaults used while the session settings are not set
+const (
+	DefaultWorkMinutes       = 25
+	DefaultShortBreakMinutes = 5
+	DefaultLongBreakMinutes  = 15
+	DefaultPomodoroCycles    = 4
+	DefaultPhaseToneDir      = "include/sounds/phases" // Holds <phase>.tone, e.g. work.tone
+)
+
+// SessionConfig configures Pomodoro and interval training sessions
+type SessionConfig struct {
+	WorkMinutes       int               `json:"work_minutes,omitempty"`        // 0 = DefaultWorkMinutes
+	ShortBreakMinutes int               `json:"short_break_minutes,omitempty"` // 0 = DefaultShortBreakMinutes
+	LongBreakMinutes  int               `json:"long_break_minutes,omitempty"`  // 0 = DefaultLongBreakMinutes
+	Cycles            int               `json:"cycles,omitempty"`              // Work phases per Pomodoro, 0 = DefaultPomodoroCycles
+	Intervals         []IntervalProgram `json:"intervals,omitempty"`
+	PhaseTones        map[string]string `json:"phase_tones,omitempty"` // Phase ("work", "short_break", "long_break", "rest", "finished") -> .tone file
+}
+
+// IntervalProgram is an interval training like 8× (20s work / 10s rest)
+type IntervalProgram struct {
+	Name        string `json:"name"`
+	Rounds      int    `json:"rounds"`
+	WorkSeconds int    `json:"work_seconds"`
+	RestSeconds int    `json:"rest_seconds"`
 }
 
 // SleepTimer represents a sleep timer configuration
 type SleepTimer struct {
-	Duration         int         `json:"duration"`           // Duration in minutes: 0 (disabled) or 5-120 (active)
-	Source           AlarmSource `json:"source"`             // Sound source: soother, mp3, radio
-	Volume           int         `json:"volume"`             // 1-100
-	AlarmSourceValue string      `json:"alarm_source_value"` // file path for .tone/.mp3 files or directory/playlist path
+	Duration         int         `json:"duration"`            // Duration in minutes, 0 disables the sleep timer
+	Until            string      `json:"until,omitempty"`     // "HH:MM": run until this time of day instead of Duration
+	UntilEnd         bool        `json:"until_end,omitempty"` // Run until the file or playlist has finished playing
+	Source           AlarmSource `json:"source"`              // Sound source: soother, mp3, radio
+	Volume           int         `json:"volume"`              // 1-100
+	AlarmSourceValue string      `json:"alarm_source_value"`  // file path for .tone/.mp3 files or directory/playlist path
+	FadeMinutes      int         `json:"fade_minutes"`        // Fade to silence over the last minutes, 0 stops abruptly; .tone sounds and mpv only
+}
+
+// Fade returns how long before the end of the sleep timer the audio starts fading out
+func (s *SleepTimer) Fade() time.Duration {
+	return time.Duration(s.FadeMinutes) * time.Minute
+}
+
+// maxSleepDuration limits typed sleep timer durations
+const maxSleepDuration = 24 * time.Hour
+
+// SetDuration sets how long the sleep timer runs from typed input: minutes ("45"), a
+// duration ("2h30m"), a time of day ("until 01:00") or "until end" of the file or
+// playlist. "off" or "0" disables the sleep timer.
+func (s *SleepTimer) SetDuration(input string) error {
+	text := strings.ToLower(strings.Join(strings.Fields(input), " "))
+
+	switch text {
+	case "", "0", "off":
+		s.Duration, s.Until, s.UntilEnd = 0, "", false
+		return nil
+	case "end", "until end", "until end of file", "until end of playlist":
+		s.Duration, s.Until, s.UntilEnd = 0, "", true
+		return nil
+	}
+
+	if clock, isUntil := strings.CutPrefix(text, "until "); isUntil {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return fmt.Errorf("invalid time %q, expected e.g. until 01:00", clock)
+		}
+		s.Duration, s.Until, s.UntilEnd = 0, t.Format("15:04"), false
+		return nil
+	}
+
+	duration, err := time.ParseDuration(text)
+	if minutes, convErr := strconv.Atoi(text); convErr == nil {
+		duration, err = time.Duration(minutes)*time.Minute, nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected e.g. 45, 2h30m, until 01:00 or until end", input)
+	}
+	if duration < time.Minute || duration > maxSleepDuration {
+		return fmt.Errorf("duration must be between 1 minute and %d hours", int(maxSleepDuration.Hours()))
+	}
+
+	// Whole minutes, rounded up
+	s.Duration, s.Until, s.UntilEnd = int((duration+time.Minute-1)/time.Minute), "", false
+	return nil
+}
+
+// Enabled reports whether a sleep timer duration is set
+func (s *SleepTimer) Enabled() bool {
+	return s.Duration > 0 || s.Until != "" || s.UntilEnd
+}
+
+// Length returns how long a sleep timer started at now runs. It is 0 when the sleep
+// timer is disabled or runs until the end of the file.
+func (s *SleepTimer) Length(now time.Time) time.Duration {
+	if s.UntilEnd {
+		return 0
+	}
+	if s.Until == "" {
+		return time.Duration(s.Duration) * time.Minute
+	}
+
+	clock, err := time.Parse("15:04", s.Until)
+	if err != nil {
+		return 0
+	}
+	end := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !end.After(now) {
+		end = time.Date(now.Year(), now.Month(), now.Day()+1, clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	}
+	return end.Sub(now)
+}
+
+// DurationText describes the sleep timer duration, e.g. "45 min", "2h30m" or "until 01:00"
+func (s *SleepTimer) DurationText() string {
+	switch {
+	case s.UntilEnd:
+		return "until end of file"
+	case s.Until != "":
+		return "until " + s.Until
+	case s.Duration == 0:
+		return "OFF"
+	case s.Duration < 60:
+		return fmt.Sprintf("%d min", s.Duration)
+	case s.Duration%60 == 0:
+		return fmt.Sprintf("%dh", s.Duration/60)
+	default:
+		return fmt.Sprintf("%dh%dm", s.Duration/60, s.Duration%60)
+	}
 }
 
 // Config represents the application configuration
@@ -48,8 +241,15 @@ type Config struct {
 	Backlight    int    `json:"backlight"`    // 1-10
 
 	// Alarms
-	Alarm1 Alarm `json:"alarm1"`
-	Alarm2 Alarm `json:"alarm2"`
+	Alarms []Alarm `json:"alarms"`
+
+	// Legacy fixed alarms, only read to migrate old config files into Alarms
+	Alarm1 *Alarm `json:"alarm1,omitempty"`
+	Alarm2 *Alarm `json:"alarm2,omitempty"`
+
+	// Holidays, on which alarms with RespectHolidays stay quiet
+	Holidays     []string `json:"holidays,omitempty"`      // Local exception dates (YYYY-MM-DD), e.g. vacation days
+	HolidayFiles []string `json:"holiday_files,omitempty"` // .ics calendars with public holidays
 
 	// Sleep Timer
 	SleepTimer SleepTimer `json:"sleep_timer"`
@@ -57,6 +257,19 @@ type Config struct {
 	// Timers
 	SnoozeMinutes int `json:"snooze_minutes"` // 5, 10, 15, 30
 
+	// Pomodoro and interval sessions
+	Session SessionConfig `json:"session"`
+
+	// Second clock
+	SecondTimezone string `json:"second_timezone,omitempty"` // IANA name shown below the main clock, empty to hide
+
+	// Smart wake window
+	ActivityFile string `json:"activity_file,omitempty"` // Touching this file ends a wake window early
+
+	// Missed alarms
+	MissedAlarmGraceMinutes int               `json:"missed_alarm_grace_minutes"` // 0 disables missed alarm detection
+	MissedAlarmAction       MissedAlarmAction `json:"missed_alarm_action"`
+
 	// Audio settings
 	PlayerCommand string `json:"player_command"` // e.g., "mpv"
 	LastRadioURL  string `json:"last_radio_url"`
@@ -70,8 +283,7 @@ type Config struct {
 	ShowNavigationBar bool `json:"show_navigation_bar"`
 	ShowSettingsBar   bool `json:"show_settings_bar"`   // show/hide [SETTINGS] [ALARM...] [SLEEP] bar
 	ShowSleepTimer    bool `json:"show_sleep_timer"`    // show/hide just [SLEEP]
-	ShowInactiveItems bool `json:"show_inactive_items"` // show/hide alarm1/2 indicator, sleep indicator if disabled
-	ShowAlarm2        bool `json:"show_alarm_2"`        // show/hide [ALARM 2]
+	ShowInactiveItems bool `json:"show_inactive_items"` // show/hide disabled alarm indicators, sleep indicator if disabled
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -82,38 +294,42 @@ func DefaultConfig() *Config {
 		FontName:     "big",
 		Brightness:   5,
 		Backlight:    5,
-		Alarm1: Alarm{
-			ID:         1,
-			Enabled:    false,
-			Time:       "07:00:00",
-			Days:       []bool{false, true, true, true, true, true, false}, // Mon-Fri
-			Source:     SourceBuzzer,
-			Volume:     50,
-			VolumeRamp: true,
-		},
-		Alarm2: Alarm{
-			ID:         2,
-			Enabled:    false,
-			Time:       "07:30:00",
-			Days:       []bool{false, true, true, true, true, true, false}, // Mon-Fri
-			Source:     SourceBuzzer,
-			Volume:     50,
-			VolumeRamp: true,
+		Alarms: []Alarm{
+			DefaultAlarm(1, "07:00:00"),
+			DefaultAlarm(2, "07:30:00"),
 		},
 		SleepTimer: SleepTimer{
-			Duration: 60, // Default 60 minutes
-			Source:   SourceSoother,
-			Volume:   30, // Lower volume for sleep timer
+			Duration:    60, // Default 60 minutes
+			Source:      SourceSoother,
+			Volume:      30, // Lower volume for sleep timer
+			FadeMinutes: 5,
+		},
+		SnoozeMinutes: 5,
+		Session: SessionConfig{
+			Intervals: []IntervalProgram{{Name: "Tabata", Rounds: 8, WorkSeconds: 20, RestSeconds: 10}},
 		},
-		SnoozeMinutes:     5,
-		PlayerCommand:     "mpv",
-		BuzzerDir:         "include/sounds/buzzer",
-		SootherDir:        "include/sounds/soother",
-		ShowNavigationBar: true,
-		ShowSettingsBar:   true,
-		ShowSleepTimer:    true,
-		ShowInactiveItems: true,
-		ShowAlarm2:        true,
+		MissedAlarmGraceMinutes: 30,
+		MissedAlarmAction:       MissedAlarmNotify,
+		PlayerCommand:           "mpv",
+		BuzzerDir:               "include/sounds/buzzer",
+		SootherDir:              "include/sounds/soother",
+		ShowNavigationBar:       true,
+		ShowSettingsBar:         true,
+		ShowSleepTimer:          true,
+		ShowInactiveItems:       true,
+	}
+}
+
+// DefaultAlarm returns a disabled Mon-Fri buzzer alarm with the given ID and time
+func DefaultAlarm(id int, alarmTime string) Alarm {
+	return Alarm{
+		ID:         id,
+		Enabled:    false,
+		Time:       alarmTime,
+		Days:       []bool{false, true, true, true, true, true, false}, // Mon-Fri
+		Source:     SourceBuzzer,
+		Volume:     50,
+		VolumeRamp: true,
 	}
 }
 
@@ -140,12 +356,136 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
 
-	// Always reset sleep timer duration to 0 on app start
-	cfg.SleepTimer.Duration = 0
+	cfg.migrateLegacyAlarms()
+	cfg.fillMissingDefaults(data)
+	for i := range cfg.Alarms {
+		cfg.Alarms[i].ResolveTimezone()
+	}
 
 	return &cfg, nil
 }
 
+// fillMissingDefaults sets the defaults of settings that config files written by older
+// versions don't contain, where the zero value would mean something else
+func (c *Config) fillMissingDefaults(data []byte) {
+	var present struct {
+		MissedAlarmGraceMinutes *int `json:"missed_alarm_grace_minutes"`
+	}
+	if err := json.Unmarshal(data, &present); err != nil {
+		return
+	}
+
+	// 0 disables missed alarm detection, upgrading shouldn't
+	if present.MissedAlarmGraceMinutes == nil {
+		c.MissedAlarmGraceMinutes = DefaultConfig().MissedAlarmGraceMinutes
+	}
+}
+
+// migrateLegacyAlarms moves the old fixed alarm1/alarm2 entries into Alarms
+func (c *Config) migrateLegacyAlarms() {
+	for _, legacy := range []*Alarm{c.Alarm1, c.Alarm2} {
+		if legacy == nil || c.FindAlarm(legacy.ID) != nil {
+			continue
+		}
+		if legacy.ID <= 0 {
+			legacy.ID = c.nextAlarmID()
+		}
+		if len(legacy.Days) != 7 {
+			legacy.Days = make([]bool, 7)
+		}
+		c.Alarms = append(c.Alarms, *legacy)
+	}
+	c.Alarm1 = nil
+	c.Alarm2 = nil
+}
+
+// FindAlarm returns the alarm with the given ID, or nil if there is none.
+// The pointer is only valid until the Alarms slice is modified.
+func (c *Config) FindAlarm(id int) *Alarm {
+	for i := range c.Alarms {
+		if c.Alarms[i].ID == id {
+			return &c.Alarms[i]
+		}
+	}
+	return nil
+}
+
+// CloneAlarms returns a deep copy of the alarm list
+func (c *Config) CloneAlarms() []Alarm {
+	alarms := make([]Alarm, len(c.Alarms))
+	for i, a := range c.Alarms {
+		alarms[i] = a.Clone()
+	}
+	return alarms
+}
+
+// AddAlarm appends a new alarm with default settings and returns its ID
+func (c *Config) AddAlarm() int {
+	a := DefaultAlarm(c.nextAlarmID(), "07:00:00")
+	c.Alarms = append(c.Alarms, a)
+	return a.ID
+}
+
+// DuplicateAlarm appends a copy of the given alarm with a new ID and returns it, or 0 if not found
+func (c *Config) DuplicateAlarm(id int) int {
+	src := c.FindAlarm(id)
+	if src == nil {
+		return 0
+	}
+
+	dup := src.Clone()
+	dup.ID = c.nextAlarmID()
+	c.Alarms = append(c.Alarms, dup)
+	return dup.ID
+}
+
+// DeleteAlarm removes the alarm with the given ID
+func (c *Config) DeleteAlarm(id int) bool {
+	for i := range c.Alarms {
+		if c.Alarms[i].ID == id {
+			c.Alarms = append(c.Alarms[:i], c.Alarms[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// nextAlarmID returns an ID higher than any alarm currently configured
+func (c *Config) nextAlarmID() int {
+	next := 1
+	for _, a := range c.Alarms {
+		if a.ID >= next {
+			next = a.ID + 1
+		}
+	}
+	return next
+}
+
+// AddHoliday adds a local exception date, keeping the list sorted and free of duplicates
+func (c *Config) AddHoliday(date string) {
+	if slices.Contains(c.Holidays, date) {
+		return
+	}
+	c.Holidays = append(c.Holidays, date)
+	slices.Sort(c.Holidays)
+}
+
+// Clone returns a deep copy of the alarm
+func (a Alarm) Clone() Alarm {
+	a.Days = append([]bool(nil), a.Days...)
+	a.ProgressiveSnooze = append([]int(nil), a.ProgressiveSnooze...)
+	a.Stages = append([]AlarmStage(nil), a.Stages...)
+	return a
+}
+
+// Name returns the alarm label, or "ALARM <id>" if no label is set
+func (a *Alarm) Name() string {
+	if a.Label != "" {
+		return a.Label
+	}
+	return fmt.Sprintf("ALARM %d", a.ID)
+}
+
 // Save saves the configuration to config.json
 func (c *Config) Save() error {
 	configPath := getConfigPath()
@@ -167,32 +507,226 @@ func (c *Config) Save() error {
 	return nil
 }
 
-// IsAlarmActive checks if an alarm should be active for the given time
-func (a *Alarm) IsAlarmActive(t time.Time) bool {
-	if !a.Enabled {
-		return false
+// IsOneShot reports whether the alarm rings once on a specific date
+func (a *Alarm) IsOneShot() bool {
+	return a.Date != ""
+}
+
+// RingDuration returns how long the alarm rings before its no-response action
+func (a *Alarm) RingDuration() time.Duration {
+	if a.MaxRingMinutes <= 0 {
+		return DefaultMaxRingMinutes * time.Minute
+	}
+	return time.Duration(a.MaxRingMinutes) * time.Minute
+}
+
+// SnoozeDuration returns the length of the snooze following the given number of earlier
+// snoozes. Progressive snooze takes precedence over the per-alarm and global durations.
+func (a *Alarm) SnoozeDuration(snoozeCount int, globalMinutes int) time.Duration {
+	minutes := globalMinutes
+	switch {
+	case len(a.ProgressiveSnooze) > 0:
+		minutes = a.ProgressiveSnooze[min(snoozeCount, len(a.ProgressiveSnooze)-1)]
+	case a.SnoozeMinutes > 0:
+		minutes = a.SnoozeMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// StageAlarm returns a copy of the alarm that plays the given stage. Only the first
+// stage ramps up, later stages start at their own volume. A wake window replaces the ramp.
+// Alarms without stages are returned as is apart from the ramp.
+func (a *Alarm) StageAlarm(stage int) Alarm {
+	stageAlarm := a.Clone()
+	if a.WakeWindowMinutes > 0 {
+		stageAlarm.VolumeRamp = false
+	}
+	if stage < 0 || stage >= len(a.Stages) {
+		return stageAlarm
+	}
+
+	s := a.Stages[stage]
+	stageAlarm.Source = s.Source
+	stageAlarm.AlarmSourceValue = s.AlarmSourceValue
+	stageAlarm.Volume = s.Volume
+	stageAlarm.VolumeRamp = stageAlarm.VolumeRamp && stage == 0
+	return stageAlarm
+}
+
+// StageDuration returns how long the given stage plays before the next one starts,
+// or 0 if it plays until the alarm stops
+func (a *Alarm) StageDuration(stage int) time.Duration {
+	if stage < 0 || stage >= len(a.Stages)-1 {
+		return 0
+	}
+	return time.Duration(a.Stages[stage].DurationMinutes) * time.Minute
+}
+
+// WakeWindow returns how long before its time the alarm starts its quiet pre-alarm
+func (a *Alarm) WakeWindow() time.Duration {
+	return time.Duration(max(0, a.WakeWindowMinutes)) * time.Minute
+}
+
+// SkipPending reports whether an occurrence is still going to be skipped
+func (a *Alarm) SkipPending(now time.Time) bool {
+	return a.SkipUntil.After(now)
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in the given location
+func ParseDate(date string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(DateLayout, date, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	return t, nil
+}
+
+// Pomodoro returns the Pomodoro phase durations and cycle count, defaults filled in
+func (s *SessionConfig) Pomodoro() (work, shortBreak, longBreak time.Duration, cycles int) {
+	orDefault := func(value, fallback int) int {
+		if value > 0 {
+			return value
+		}
+		return fallback
 	}
+	work = time.Duration(orDefault(s.WorkMinutes, DefaultWorkMinutes)) * time.Minute
+	shortBreak = time.Duration(orDefault(s.ShortBreakMinutes, DefaultShortBreakMinutes)) * time.Minute
+	longBreak = time.Duration(orDefault(s.LongBreakMinutes, DefaultLongBreakMinutes)) * time.Minute
+	return work, shortBreak, longBreak, orDefault(s.Cycles, DefaultPomodoroCycles)
+}
 
-	// Check if today is enabled
-	weekday := int(t.Weekday()) // Sunday = 0
-	if !a.Days[weekday] {
-		return false
+// PhaseTone returns the .tone file played when a session enters the given phase
+func (s *SessionConfig) PhaseTone(phase string) string {
+	if tone := s.PhaseTones[phase]; tone != "" {
+		return tone
 	}
+	return DefaultPhaseToneDir + "/" + phase + ".tone"
+}
 
-	// Check if current time matches alarm time (within 1 minute)
-	currentTimeStr := t.Format("15:04:05")
-	alarmTime := a.Time
+// String describes the program, e.g. "Tabata: 8× 20s / 10s"
+func (p IntervalProgram) String() string {
+	work := time.Duration(p.WorkSeconds) * time.Second
+	rest := time.Duration(p.RestSeconds) * time.Second
+	return fmt.Sprintf("%s: %d× %v / %v", p.Name, p.Rounds, work, rest)
+}
+
+// ParseIntervalProgram parses a program like "tabata 8x20s/10s" or "8x 40s/20s"
+func ParseIntervalProgram(input string) (IntervalProgram, error) {
+	fields := strings.Fields(input)
+	invalid := fmt.Errorf("expected e.g. tabata 8x20s/10s")
+	if len(fields) == 0 {
+		return IntervalProgram{}, invalid
+	}
+
+	// The rounds and durations may be separated by spaces: "8x 20s / 10s"
+	spec := ""
+	name := fields
+	for i := range fields {
+		if strings.ContainsAny(fields[i], "xX×") && fields[i][0] >= '0' && fields[i][0] <= '9' {
+			spec, name = strings.Join(fields[i:], ""), fields[:i]
+			break
+		}
+	}
 
-	// Handle both HH:MM:SS and HH:MM formats for backwards compatibility
-	if len(alarmTime) == 5 { // HH:MM format
-		alarmTime += ":00"
+	roundsText, durations, found := strings.Cut(strings.NewReplacer("X", "x", "×", "x").Replace(spec), "x")
+	workText, restText, hasRest := strings.Cut(durations, "/")
+	if !found || !hasRest {
+		return IntervalProgram{}, invalid
 	}
 
-	AlarmTriggered := currentTimeStr == alarmTime
+	rounds, err := strconv.Atoi(roundsText)
+	if err != nil || rounds < 1 || rounds > 99 {
+		return IntervalProgram{}, fmt.Errorf("rounds must be between 1 and 99")
+	}
+	work, err := time.ParseDuration(workText)
+	if err != nil || work < time.Second {
+		return IntervalProgram{}, fmt.Errorf("invalid work duration %q", workText)
+	}
+	rest, err := time.ParseDuration(restText)
+	if err != nil || rest < 0 {
+		return IntervalProgram{}, fmt.Errorf("invalid rest duration %q", restText)
+	}
 
-	// log.Println("Alarm triggered: ", AlarmTriggered)
+	program := IntervalProgram{
+		Name:        strings.Join(name, " "),
+		Rounds:      rounds,
+		WorkSeconds: int(work.Seconds()),
+		RestSeconds: int(rest.Seconds()),
+	}
+	if program.Name == "" {
+		program.Name = "Intervals"
+	}
+	return program, nil
+}
 
-	return AlarmTriggered
+// LoadTimezone resolves an IANA timezone name; an empty name means local time
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// ResolveTimezone loads the alarm's timezone once, so Location doesn't read zoneinfo
+// on every call. Call it after Timezone was set directly; SetTimezone does it already.
+func (a *Alarm) ResolveTimezone() {
+	loc, err := LoadTimezone(a.Timezone)
+	a.zone = &zone{name: a.Timezone, loc: loc, err: err}
+}
+
+// SetTimezone validates and sets the alarm's timezone, an empty name means local time
+func (a *Alarm) SetTimezone(name string) error {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return err
+	}
+	a.Timezone = name
+	a.zone = &zone{name: name, loc: loc}
+	return nil
+}
+
+// TimezoneError returns why the alarm's timezone can't be used, or nil
+func (a *Alarm) TimezoneError() error {
+	if a.zone != nil && a.zone.name == a.Timezone {
+		return a.zone.err
+	}
+	_, err := LoadTimezone(a.Timezone)
+	return err
+}
+
+// Location returns the timezone the alarm time refers to, local time if none or an
+// unknown one is set
+func (a *Alarm) Location() *time.Location {
+	if a.zone != nil && a.zone.name == a.Timezone {
+		if a.zone.err != nil {
+			return time.Local
+		}
+		return a.zone.loc
+	}
+
+	// Not resolved yet, e.g. an alarm that wasn't loaded from the config file
+	loc, err := LoadTimezone(a.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// ClockTime parses the alarm time in HH:MM:SS or HH:MM format
+func (a *Alarm) ClockTime() (hour, minute, second int, err error) {
+	layout := "15:04:05"
+	if len(a.Time) == 5 { // HH:MM format
+		layout = "15:04"
+	}
+	t, err := time.Parse(layout, a.Time)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid alarm time %q: %v", a.Time, err)
+	}
+	return t.Hour(), t.Minute(), t.Second(), nil
 }
 
 // FormatTime formats time according to 12/24 hour setting
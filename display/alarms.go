@@ -0,0 +1,824 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wecker/alarm"
+	"wecker/config"
+	"wecker/timer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// alarmListPageSize is the number of alarms visible at once in the alarm list
+const alarmListPageSize = 8
+
+// alarmEditItem identifies an entry of the alarm edit menu
+type alarmEditItem int
+
+const (
+	alarmItemEnabled alarmEditItem = iota
+	alarmItemSkip
+	alarmItemLabel
+	alarmItemTime
+	alarmItemTimezone
+	alarmItemWakeWindow
+	alarmItemDays
+	alarmItemDate
+	alarmItemStartDate
+	alarmItemEndDate
+	alarmItemRecurrence
+	alarmItemHolidays
+	alarmItemVolume
+	alarmItemSource
+	alarmItemSourceValue
+	alarmItemStages
+	alarmItemChallenge
+	alarmItemChallengeCount
+	alarmItemSnooze
+	alarmItemMaxRing
+	alarmItemNoResponse
+	alarmItemMaxSnoozes
+	alarmItemBack
+)
+
+// alarmEditItems returns the alarm edit menu entries available for the given alarm
+func alarmEditItems(a *config.Alarm) []alarmEditItem {
+	items := []alarmEditItem{
+		alarmItemEnabled,
+		alarmItemSkip,
+		alarmItemLabel,
+		alarmItemTime,
+		alarmItemTimezone,
+		alarmItemWakeWindow,
+		alarmItemDate,
+	}
+
+	// Repeat options don't apply to one-shot alarms; a recurrence replaces the weekdays
+	if !a.IsOneShot() {
+		if a.Recurrence == "" {
+			items = append(items, alarmItemDays)
+		}
+		items = append(items, alarmItemRecurrence, alarmItemStartDate, alarmItemEndDate, alarmItemHolidays)
+	}
+
+	items = append(items, alarmItemVolume, alarmItemSource)
+
+	// Add source-specific options
+	if a.Source == config.SourceBuzzer || a.Source == config.SourceMP3 || a.Source == config.SourceRadio {
+		items = append(items, alarmItemSourceValue)
+	}
+
+	items = append(items, alarmItemStages, alarmItemChallenge)
+	if a.Challenge != config.ChallengeNone {
+		items = append(items, alarmItemChallengeCount)
+	}
+
+	return append(items, alarmItemSnooze, alarmItemMaxRing, alarmItemNoResponse, alarmItemMaxSnoozes, alarmItemBack)
+}
+
+// currentAlarmEditItem returns the alarm edit menu entry under the cursor
+func (m Model) currentAlarmEditItem() alarmEditItem {
+	items := alarmEditItems(m.getCurrentAlarm())
+	if m.app.selectedMenu < 0 || m.app.selectedMenu >= len(items) {
+		return alarmItemBack
+	}
+	return items[m.app.selectedMenu]
+}
+
+// selectAlarmEditItem moves the alarm edit menu cursor to the given entry
+func (m Model) selectAlarmEditItem(item alarmEditItem) {
+	m.app.selectedMenu = 0
+	for i, it := range alarmEditItems(m.getCurrentAlarm()) {
+		if it == item {
+			m.app.selectedMenu = i
+			return
+		}
+	}
+}
+
+// alarmEditItemText returns the menu text for an alarm edit entry
+func (m Model) alarmEditItemText(a *config.Alarm, item alarmEditItem) string {
+	switch item {
+	case alarmItemEnabled:
+		return fmt.Sprintf("Enabled: %s", getBoolText(a.Enabled))
+	case alarmItemSkip:
+		if !a.SkipPending(time.Now()) {
+			return "Skip Next: OFF"
+		}
+		return fmt.Sprintf("Skip Next: %s", a.SkipUntil.Format("Mon 02 Jan 15:04"))
+	case alarmItemLabel:
+		label := a.Label
+		if label == "" {
+			label = "<not set>"
+		}
+		return fmt.Sprintf("Label: %s", label)
+	case alarmItemTime:
+		if recurrence, err := alarm.ParseRecurrence(a.Recurrence); err == nil && !recurrence.UsesAlarmTime() {
+			return fmt.Sprintf("Time: %s (ignored, cron sets the time)", a.Time[:5])
+		}
+		return fmt.Sprintf("Time: %s", a.Time[:5])
+	case alarmItemTimezone:
+		return fmt.Sprintf("Timezone: %s", timezoneText(a))
+	case alarmItemWakeWindow:
+		if a.WakeWindowMinutes <= 0 {
+			return "Wake Window: OFF"
+		}
+		hour, minute, _, err := a.ClockTime()
+		if err != nil {
+			return fmt.Sprintf("Wake Window: %d min", a.WakeWindowMinutes)
+		}
+		start := time.Date(2000, 1, 1, hour, minute, 0, 0, time.Local).Add(-a.WakeWindow())
+		return fmt.Sprintf("Wake Window: %d min (from %s)", a.WakeWindowMinutes, start.Format("15:04"))
+	case alarmItemDays:
+		return fmt.Sprintf("Days: %s", m.getActiveDaysString(a.Days))
+	case alarmItemDate:
+		if !a.IsOneShot() {
+			return "Date: <repeat weekly>"
+		}
+		return fmt.Sprintf("Date: %s (once)", a.Date)
+	case alarmItemRecurrence:
+		if a.Recurrence == "" {
+			return "Recurrence: <weekdays>"
+		}
+		recurrence, err := alarm.ParseRecurrence(a.Recurrence)
+		if err != nil {
+			return fmt.Sprintf("Recurrence: %s (invalid: %v)", a.Recurrence, err)
+		}
+		if recurrence.NeedsStartDate() && a.StartDate == "" {
+			return fmt.Sprintf("Recurrence: %s (needs a start date)", a.Recurrence)
+		}
+		return fmt.Sprintf("Recurrence: %s", a.Recurrence)
+	case alarmItemStartDate:
+		return fmt.Sprintf("Start Date: %s", dateOrNotSet(a.StartDate))
+	case alarmItemEndDate:
+		return fmt.Sprintf("End Date: %s", dateOrNotSet(a.EndDate))
+	case alarmItemHolidays:
+		return fmt.Sprintf("Skip Holidays: %s", getBoolText(a.RespectHolidays))
+	case alarmItemVolume:
+		return fmt.Sprintf("Volume: %d%%", a.Volume)
+	case alarmItemSource:
+		return fmt.Sprintf("Source: %s", a.Source)
+	case alarmItemSourceValue:
+		value := a.AlarmSourceValue
+		if value == "" {
+			value = "<not set>"
+		}
+		switch a.Source {
+		case config.SourceMP3:
+			return fmt.Sprintf("MP3 Path: %s", value)
+		case config.SourceRadio:
+			return fmt.Sprintf("Radio URL: %s", value)
+		default:
+			return fmt.Sprintf("Tone: %s", value)
+		}
+	case alarmItemStages:
+		return fmt.Sprintf("Stages: %s", stagesSummary(a))
+	case alarmItemChallenge:
+		if a.Challenge == config.ChallengeNone {
+			return "Dismiss Challenge: OFF"
+		}
+		return fmt.Sprintf("Dismiss Challenge: %s", a.Challenge)
+	case alarmItemChallengeCount:
+		switch a.Challenge {
+		case config.ChallengeMath:
+			return fmt.Sprintf("Challenge Size: %d problems", challengeCount(a))
+		case config.ChallengePhrase:
+			return fmt.Sprintf("Challenge Size: %d words", challengeCount(a))
+		default:
+			return fmt.Sprintf("Challenge Size: %d keys", challengeCount(a))
+		}
+	case alarmItemSnooze:
+		if len(a.ProgressiveSnooze) == 0 && a.SnoozeMinutes <= 0 {
+			return fmt.Sprintf("Snooze: global (%d min)", m.app.config.SnoozeMinutes)
+		}
+		return fmt.Sprintf("Snooze: %s min", snoozeText(a))
+	case alarmItemMaxRing:
+		return fmt.Sprintf("Max Ring Time: %s", formatMinutesOption(ringMinutes(a)))
+	case alarmItemNoResponse:
+		return fmt.Sprintf("No Response: %s", noResponseAction(a))
+	case alarmItemMaxSnoozes:
+		if a.MaxSnoozes <= 0 {
+			return "Max Snoozes: unlimited"
+		}
+		return fmt.Sprintf("Max Snoozes: %d", a.MaxSnoozes)
+	default:
+		return "Back"
+	}
+}
+
+// saveAlarms persists the config and hands the alarm scheduler a copy of the changed alarms
+func (m Model) saveAlarms() {
+	m.app.config.Save()
+	m.app.alarmManager.UpdateAlarms(m.app.config.Alarms)
+}
+
+// saveSettings persists the config and hands the alarm scheduler a copy of the changed
+// global settings
+func (m Model) saveSettings() {
+	m.app.config.Save()
+	m.app.alarmManager.UpdateSettings(m.app.config)
+}
+
+// skipNext makes an alarm ignore its next occurrence, or the one after an occurrence
+// that is already skipped
+func (m Model) skipNext(a *config.Alarm) {
+	if next, ok := m.app.alarmManager.NextOccurrence(a.ID); ok {
+		a.SkipUntil = next
+		m.saveAlarms()
+	}
+}
+
+// clearSkip lets an alarm ring at its next occurrence again
+func (m Model) clearSkip(a *config.Alarm) {
+	a.SkipUntil = time.Time{}
+	m.saveAlarms()
+}
+
+// alarmIndex returns the position of an alarm in the alarm list, or 0 if not found
+func (m Model) alarmIndex(alarmID int) int {
+	for i, a := range m.app.config.Alarms {
+		if a.ID == alarmID {
+			return i
+		}
+	}
+	return 0
+}
+
+// handleAlarmListKey handles the add/duplicate/delete shortcuts of the alarm list
+func (m Model) handleAlarmListKey(key string) (tea.Model, tea.Cmd) {
+	alarms := m.app.config.Alarms
+	m.app.inputError = ""
+
+	switch key {
+	case "a": // Add
+		id := m.app.config.AddAlarm()
+		m.saveAlarms()
+		m.app.selectedMenu = m.alarmIndex(id)
+	case "d": // Duplicate
+		if m.app.selectedMenu < len(alarms) {
+			if id := m.app.config.DuplicateAlarm(alarms[m.app.selectedMenu].ID); id != 0 {
+				m.saveAlarms()
+				m.app.selectedMenu = m.alarmIndex(id)
+			}
+		}
+	case "x", "delete": // Delete
+		if m.app.selectedMenu < len(alarms) {
+			id := alarms[m.app.selectedMenu].ID
+			// Deleting would silence the alarm without its dismiss challenge
+			if m.app.alarmManager.IsAlarmActive(id) {
+				m.app.inputError = "Stop the alarm before deleting it"
+				break
+			}
+			m.app.config.DeleteAlarm(id)
+			m.saveAlarms()
+			if m.app.selectedMenu >= len(m.app.config.Alarms) && m.app.selectedMenu > 0 {
+				m.app.selectedMenu--
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// scrollWindow returns the visible range [start, end) of a list so that selected stays in view
+func scrollWindow(selected, total, size int) (int, int) {
+	if total <= size {
+		return 0, total
+	}
+	start := selected - size/2
+	if start < 0 {
+		start = 0
+	}
+	if start > total-size {
+		start = total - size
+	}
+	return start, start + size
+}
+
+// Render the scrollable alarm list
+func (m Model) renderAlarmList() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("🔔 ALARMS"))
+	content.WriteString("\n\n")
+
+	alarms := m.app.config.Alarms
+	activeAlarms := m.app.alarmManager.GetActiveAlarms()
+
+	if len(alarms) == 0 {
+		content.WriteString("   No alarms configured\n")
+	}
+
+	start, end := scrollWindow(m.app.selectedMenu, len(alarms), alarmListPageSize)
+	if start > 0 {
+		content.WriteString(m.app.instructionStyle.Render("   ↑ more"))
+		content.WriteString("\n")
+	}
+
+	for i := start; i < end; i++ {
+		a := &alarms[i]
+
+		status := getBoolText(a.Enabled)
+		if activeAlarm, isActive := activeAlarms[a.ID]; isActive {
+			status = activeAlarmStatus(activeAlarm)
+		}
+
+		next := "-"
+		if nextFire := m.app.alarmManager.NextFire(a.ID); !nextFire.IsZero() {
+			next = nextFire.Format("Mon 02 Jan 15:04")
+		}
+
+		line := fmt.Sprintf("%-20s %s  %-7s  [%s]  next: %s", a.Name(), alarmTimeText(a), m.getScheduleString(a), status, next)
+		if i == m.app.selectedMenu {
+			content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" > %s ", line)))
+		} else {
+			content.WriteString(fmt.Sprintf("   %s", line))
+		}
+		content.WriteString("\n")
+	}
+
+	if end < len(alarms) {
+		content.WriteString(m.app.instructionStyle.Render("   ↓ more"))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	if m.app.inputError != "" {
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+		content.WriteString("\n\n")
+	}
+	content.WriteString(m.app.instructionStyle.Render("↑↓ to navigate  •  ENTER to edit  •  A add  •  D duplicate  •  X delete  •  ESC to return"))
+
+	return content.String()
+}
+
+// Render alarm label input screen
+func (m Model) renderAlarmLabel() string {
+	var content strings.Builder
+
+	title := fmt.Sprintf("🏷️ LABEL FOR %s", m.getCurrentAlarm().Name())
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString("Enter a name for this alarm (leave empty for the default):\n")
+	content.WriteString("Examples: Work, Weekend, Flight\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type label  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
+
+// maxRingOptions are the selectable maximum ring times in minutes
+var maxRingOptions = []int{5, 10, 15, 30, 60, 120}
+
+// challengeCountOptions are the selectable challenge sizes
+var challengeCountOptions = []int{1, 2, 3, 4, 5, 6, 8, 10}
+
+// cycleChallenge advances the dismiss challenge: off -> math -> phrase -> sequence
+func cycleChallenge(a *config.Alarm) {
+	switch a.Challenge {
+	case config.ChallengeNone:
+		a.Challenge = config.ChallengeMath
+	case config.ChallengeMath:
+		a.Challenge = config.ChallengePhrase
+	case config.ChallengePhrase:
+		a.Challenge = config.ChallengeSequence
+	default:
+		a.Challenge = config.ChallengeNone
+	}
+	// Sizes differ per kind, start from the default again
+	a.ChallengeCount = 0
+}
+
+// wakeWindowOptions are the selectable wake windows in minutes, 0 means off
+var wakeWindowOptions = []int{0, 10, 15, 20, 30, 45, 60}
+
+// maxSnoozeOptions are the selectable snooze limits, 0 means unlimited
+var maxSnoozeOptions = []int{0, 1, 2, 3, 5, 10}
+
+// ringMinutes returns the effective maximum ring time of an alarm in minutes
+func ringMinutes(a *config.Alarm) int {
+	return int(a.RingDuration() / time.Minute)
+}
+
+// noResponseAction returns the effective no-response action of an alarm
+func noResponseAction(a *config.Alarm) config.NoResponseAction {
+	if a.NoResponseAction == "" {
+		return config.NoResponseStop
+	}
+	return a.NoResponseAction
+}
+
+// cycleNoResponseAction advances the no-response action: stop -> snooze -> escalate
+func cycleNoResponseAction(a *config.Alarm) {
+	switch noResponseAction(a) {
+	case config.NoResponseStop:
+		a.NoResponseAction = config.NoResponseSnooze
+	case config.NoResponseSnooze:
+		a.NoResponseAction = config.NoResponseEscalate
+	default:
+		a.NoResponseAction = config.NoResponseStop
+	}
+}
+
+// snoozeText formats the per-alarm snooze setting as typed on the snooze screen, e.g. "20" or "10/7/5/3"
+func snoozeText(a *config.Alarm) string {
+	if len(a.ProgressiveSnooze) > 0 {
+		parts := make([]string, len(a.ProgressiveSnooze))
+		for i, minutes := range a.ProgressiveSnooze {
+			parts[i] = strconv.Itoa(minutes)
+		}
+		return strings.Join(parts, "/")
+	}
+	if a.SnoozeMinutes > 0 {
+		return strconv.Itoa(a.SnoozeMinutes)
+	}
+	return ""
+}
+
+// snoozeInputValidator validates characters for snooze input (digits and slash)
+func snoozeInputValidator(key string) bool {
+	return (key >= "0" && key <= "9") || key == "/"
+}
+
+// Simple snooze input - minutes or a progressive list like 10/7/5/3
+func (m Model) handleSnoozeInput(key string) (tea.Model, tea.Cmd) {
+	handleGenericInput(&m.app.customPathInput, key, 32, snoozeInputValidator)
+	m.app.inputError = ""
+	return m, nil
+}
+
+// parseAndSetSnooze validates the snooze input and stores it on the current alarm
+func (m Model) parseAndSetSnooze() bool {
+	input := strings.TrimSpace(m.app.customPathInput)
+
+	var minutes []int
+	if input != "" {
+		for _, part := range strings.Split(input, "/") {
+			value, err := strconv.Atoi(part)
+			if err != nil || value < 1 || value > 120 {
+				m.app.inputError = fmt.Sprintf("invalid snooze minutes %q, expected 1-120", part)
+				return false
+			}
+			minutes = append(minutes, value)
+		}
+	}
+
+	a := m.getCurrentAlarm()
+	a.SnoozeMinutes = 0
+	a.ProgressiveSnooze = nil
+	switch len(minutes) {
+	case 0:
+		// Use the global snooze duration
+	case 1:
+		a.SnoozeMinutes = minutes[0]
+	default:
+		a.ProgressiveSnooze = minutes
+	}
+
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return true
+}
+
+// Render snooze input screen
+func (m Model) renderSnoozeInput() string {
+	var content strings.Builder
+
+	title := fmt.Sprintf("💤 SNOOZE FOR %s", m.getCurrentAlarm().Name())
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("Snooze minutes for this alarm (leave empty for the global %d min):\n", m.app.config.SnoozeMinutes))
+	content.WriteString("Examples: 20  •  10/7/5/3 (each snooze shorter, the last one repeats)\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type numbers and /  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
+
+// activeAlarmStatus describes a ringing or snoozed alarm including its snooze count
+func activeAlarmStatus(activeAlarm *alarm.ActiveAlarm) string {
+	status := "ACTIVE"
+	switch {
+	case activeAlarm.State == alarm.StatePreAlarm:
+		return fmt.Sprintf("WAKING until %s", activeAlarm.Deadline.Format("15:04"))
+	case activeAlarm.State == alarm.StateSnoozed:
+		status = "SNOOZED"
+	case activeAlarm.Escalated:
+		status = "ESCALATED"
+	}
+
+	if limit := activeAlarm.Alarm.MaxSnoozes; limit > 0 {
+		status += fmt.Sprintf(" %d/%d", activeAlarm.SnoozeCount, limit)
+		if activeAlarm.SnoozesLeft() == 0 && activeAlarm.State != alarm.StateSnoozed {
+			status += " NO SNOOZE"
+		}
+	} else if activeAlarm.SnoozeCount > 0 {
+		status += fmt.Sprintf(" x%d", activeAlarm.SnoozeCount)
+	}
+
+	if stages := len(activeAlarm.Alarm.Stages); stages > 0 {
+		status += fmt.Sprintf(" STAGE %d/%d %s", activeAlarm.Stage+1, stages, activeAlarm.Alarm.Stages[activeAlarm.Stage].Source)
+	}
+
+	return status
+}
+
+// missedGraceOptions are the selectable missed alarm grace windows in minutes
+var missedGraceOptions = []int{0, 15, 30, 60, 120}
+
+// sleepExtension is the time added to a running sleep timer with +
+const sleepExtension = 5 * time.Minute
+
+// handleMainClockKey handles the single-key shortcuts of the main clock
+func (m Model) handleMainClockKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "+", "=": // More time for the sleep timer
+		m.app.timerManager.ExtendTimer(timer.TypeSleep, sleepExtension)
+	case "p": // Pause or resume the sleep timer, the sleep audio keeps playing
+		if !m.app.timerManager.ResumeTimer(timer.TypeSleep) {
+			m.app.timerManager.PauseTimer(timer.TypeSleep)
+		}
+	case "x": // Dismiss missed alarm notices
+		m.app.alarmManager.DismissMissed()
+	case "n": // Skip the next alarm that is going to ring
+		if id, ok := m.nextRingingAlarm(); ok {
+			m.skipNext(m.app.config.FindAlarm(id))
+		}
+	case "u": // Undo all pending skips
+		for i := range m.app.config.Alarms {
+			if a := &m.app.config.Alarms[i]; a.SkipPending(time.Now()) {
+				m.clearSkip(a)
+			}
+		}
+	}
+	return m, nil
+}
+
+// nextRingingAlarm returns the ID of the enabled alarm with the earliest planned occurrence
+func (m Model) nextRingingAlarm() (int, bool) {
+	var earliest time.Time
+	id := 0
+	for _, a := range m.app.config.Alarms {
+		next := m.app.alarmManager.NextFire(a.ID)
+		if !a.Enabled || next.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest, id = next, a.ID
+		}
+	}
+	return id, !earliest.IsZero()
+}
+
+// Render notices for alarms that passed while wecker wasn't running or the machine slept
+func (m Model) renderMissedAlarms() string {
+	missed := m.app.alarmManager.GetMissedAlarms()
+	if len(missed) == 0 {
+		return ""
+	}
+
+	var notices []string
+	for _, a := range m.app.config.Alarms {
+		if occurrence, exists := missed[a.ID]; exists {
+			notices = append(notices, fmt.Sprintf("⚠️  MISSED %s: %s", a.Name(), occurrence.Format("Mon 15:04")))
+		}
+	}
+	notices = append(notices, "(X to dismiss)")
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFF00")).
+		Align(lipgloss.Center).
+		Render(strings.Join(notices, "    "))
+}
+
+// getScheduleString summarizes when an alarm repeats, e.g. "MTWTF" or "once 03 Nov"
+func (m Model) getScheduleString(a *config.Alarm) string {
+	if a.IsOneShot() {
+		if day, err := config.ParseDate(a.Date, time.Local); err == nil {
+			return "once " + day.Format("02 Jan")
+		}
+		return "once " + a.Date
+	}
+
+	schedule := m.getActiveDaysString(a.Days)
+	if a.Recurrence != "" {
+		schedule = a.Recurrence
+	}
+	if a.StartDate != "" || a.EndDate != "" {
+		schedule += fmt.Sprintf(" %s..%s", a.StartDate, a.EndDate)
+	}
+	return schedule
+}
+
+// dateOrNotSet returns the date or a placeholder for empty dates
+func dateOrNotSet(date string) string {
+	if date == "" {
+		return "<not set>"
+	}
+	return date
+}
+
+// alarmDateField returns the alarm field edited by a date menu entry
+func alarmDateField(a *config.Alarm, item alarmEditItem) *string {
+	switch item {
+	case alarmItemStartDate:
+		return &a.StartDate
+	case alarmItemEndDate:
+		return &a.EndDate
+	default:
+		return &a.Date
+	}
+}
+
+// dateInputValidator validates characters for date input (digits and dash)
+func dateInputValidator(key string) bool {
+	return (key >= "0" && key <= "9") || key == "-"
+}
+
+// Simple date input - YYYY-MM-DD
+func (m Model) handleDateInput(key string) (tea.Model, tea.Cmd) {
+	handleGenericInput(&m.app.dateInput, key, 10, dateInputValidator)
+	m.app.inputError = ""
+	return m, nil
+}
+
+// parseAndSetDate validates the date input and stores it on the current alarm
+func (m Model) parseAndSetDate() bool {
+	a := m.getCurrentAlarm()
+	date := strings.TrimSpace(m.app.dateInput)
+
+	if date != "" {
+		if _, err := config.ParseDate(date, time.Local); err != nil {
+			m.app.inputError = err.Error()
+			return false
+		}
+	}
+
+	// Keep the repeat range consistent
+	start, end := a.StartDate, a.EndDate
+	switch m.app.dateField {
+	case alarmItemStartDate:
+		start = date
+	case alarmItemEndDate:
+		end = date
+	}
+	if start != "" && end != "" && end < start {
+		m.app.inputError = "end date is before start date"
+		return false
+	}
+
+	*alarmDateField(a, m.app.dateField) = date
+
+	// Setting a date re-arms a one-shot alarm that already rang
+	if m.app.dateField == alarmItemDate && date != "" {
+		a.Enabled = true
+	}
+
+	m.app.dateInput = ""
+	m.app.inputError = ""
+	return true
+}
+
+// Render date input screen
+func (m Model) renderDateInput() string {
+	var content strings.Builder
+
+	var title, prompt string
+	switch m.app.dateField {
+	case alarmItemStartDate:
+		title = fmt.Sprintf("📅 START DATE FOR %s", m.getCurrentAlarm().Name())
+		prompt = "First day the weekly repeat rings (leave empty for no limit):"
+	case alarmItemEndDate:
+		title = fmt.Sprintf("📅 END DATE FOR %s", m.getCurrentAlarm().Name())
+		prompt = "Last day the weekly repeat rings (leave empty for no limit):"
+	default:
+		title = fmt.Sprintf("📅 DATE FOR %s", m.getCurrentAlarm().Name())
+		prompt = "Ring once on this date (leave empty to repeat weekly):"
+	}
+
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString(prompt + "\n")
+	content.WriteString("Format: YYYY-MM-DD, e.g. 2026-11-03\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.dateInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type numbers and -  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
+
+// recurrencePreviewCount is the number of upcoming occurrences previewed while editing a recurrence
+const recurrencePreviewCount = 3
+
+// parseAndSetRecurrence validates the recurrence input and stores it on the current alarm
+func (m Model) parseAndSetRecurrence() bool {
+	a := m.getCurrentAlarm()
+	expr := strings.TrimSpace(m.app.customPathInput)
+	if expr != "" {
+		recurrence, err := alarm.ParseRecurrence(expr)
+		if err != nil {
+			m.app.inputError = err.Error()
+			return false
+		}
+		// A bare FREQ=WEEKLY repeats on the day it was saved, like an RRULE starting today
+		if recurrence.NeedsStartDate() && a.StartDate == "" {
+			a.StartDate = time.Now().In(a.Location()).Format(config.DateLayout)
+		}
+	}
+
+	a.Recurrence = expr
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return true
+}
+
+// Render recurrence input screen with live validation and a preview
+func (m Model) renderRecurrenceInput() string {
+	var content strings.Builder
+
+	a := m.getCurrentAlarm()
+	title := fmt.Sprintf("🔁 RECURRENCE FOR %s", a.Name())
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString("Enter an RRULE or a cron expression (leave empty to use the weekdays):\n")
+	content.WriteString("Examples: FREQ=WEEKLY;INTERVAL=2;BYDAY=MO  •  FREQ=MONTHLY;BYDAY=-1FR\n")
+	content.WriteString("          FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1  •  30 6 * * 1-5\n")
+	content.WriteString("INTERVAL counts from the start date\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+	content.WriteString("\n\n")
+
+	expr := strings.TrimSpace(m.app.customPathInput)
+	errText := m.app.inputError
+	startDate := a.StartDate
+	if expr != "" {
+		if recurrence, err := alarm.ParseRecurrence(expr); err != nil {
+			errText = err.Error()
+		} else if recurrence.NeedsStartDate() && startDate == "" {
+			// Saving starts the series today
+			startDate = time.Now().In(a.Location()).Format(config.DateLayout)
+		}
+	}
+
+	if errText != "" {
+		content.WriteString(m.app.errorStyle.Render(errText))
+		content.WriteString("\n\n")
+	} else {
+		// Preview the next occurrences with the rule as typed
+		preview := a.Clone()
+		preview.Recurrence = expr
+		preview.StartDate = startDate
+		occurrences := alarm.NextOccurrences(&preview, time.Now(), recurrencePreviewCount)
+
+		content.WriteString("Next occurrences:\n")
+		if len(occurrences) == 0 {
+			content.WriteString("   none\n")
+		}
+		for _, occurrence := range occurrences {
+			content.WriteString(fmt.Sprintf("   %s\n", occurrence.Format("Mon 02 Jan 2006 15:04")))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(m.app.instructionStyle.Render("Type rule  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
@@ -0,0 +1,279 @@
+package display
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"wecker/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Default sizes of the dismiss challenges when an alarm doesn't set ChallengeCount
+const (
+	defaultMathProblems   = 3
+	defaultPhraseWords    = 4
+	defaultSequenceLength = 6
+)
+
+// challengeWords are the words random phrases are built from
+var challengeWords = []string{
+	"coffee", "morning", "window", "sunrise", "kettle", "blanket", "garden", "yellow",
+	"bicycle", "orange", "pillow", "tuesday", "breakfast", "shower", "mountain", "river",
+	"teacup", "sparrow", "marble", "pepper", "lantern", "meadow", "copper", "thunder",
+}
+
+// challengeKeys are the keys a key sequence challenge is built from
+var challengeKeys = []string{"up", "down", "left", "right"}
+
+// challengeKeySymbols maps sequence keys to the arrows shown on screen
+var challengeKeySymbols = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→"}
+
+// dismissChallenge holds the progress of the challenge that stops a ringing alarm
+type dismissChallenge struct {
+	alarmID  int
+	kind     config.DismissChallenge
+	total    int      // Problems to solve, 1 for phrases and sequences
+	solved   int      // Problems solved so far
+	prompt   string   // Current math problem or phrase
+	answer   string   // Expected input for prompt
+	sequence []string // Keys to press for sequence challenges
+	input    string
+	mistake  string // Feedback on the last wrong answer
+}
+
+// newDismissChallenge creates the challenge configured on an alarm
+func newDismissChallenge(alarmID int, a *config.Alarm) *dismissChallenge {
+	c := &dismissChallenge{alarmID: alarmID, kind: a.Challenge, total: 1}
+
+	switch a.Challenge {
+	case config.ChallengeMath:
+		c.total = challengeCount(a)
+		c.nextProblem()
+	case config.ChallengePhrase:
+		c.newPhrase(challengeCount(a))
+	case config.ChallengeSequence:
+		c.sequence = make([]string, challengeCount(a))
+		for i := range c.sequence {
+			c.sequence[i] = challengeKeys[rand.IntN(len(challengeKeys))]
+		}
+	}
+
+	return c
+}
+
+// challengeCount returns the configured challenge size or the default of its kind
+func challengeCount(a *config.Alarm) int {
+	if a.ChallengeCount > 0 {
+		return a.ChallengeCount
+	}
+	switch a.Challenge {
+	case config.ChallengeMath:
+		return defaultMathProblems
+	case config.ChallengePhrase:
+		return defaultPhraseWords
+	default:
+		return defaultSequenceLength
+	}
+}
+
+// nextProblem sets up a new arithmetic problem like "7 × 8 + 23"
+func (c *dismissChallenge) nextProblem() {
+	a, b, summand := 3+rand.IntN(10), 3+rand.IntN(10), 10+rand.IntN(90)
+	c.prompt = fmt.Sprintf("%d × %d + %d", a, b, summand)
+	c.answer = strconv.Itoa(a*b + summand)
+	c.input = ""
+}
+
+// newPhrase sets up a random phrase of the given number of words
+func (c *dismissChallenge) newPhrase(words int) {
+	phrase := make([]string, words)
+	for i := range phrase {
+		phrase[i] = challengeWords[rand.IntN(len(challengeWords))]
+	}
+	c.prompt = strings.Join(phrase, " ")
+	c.answer = c.prompt
+	c.input = ""
+}
+
+// done reports whether the challenge has been passed
+func (c *dismissChallenge) done() bool {
+	if c.kind == config.ChallengeSequence {
+		return c.solved == len(c.sequence)
+	}
+	return c.solved >= c.total
+}
+
+// submit checks a typed answer of a math or phrase challenge
+func (c *dismissChallenge) submit() {
+	if strings.TrimSpace(c.input) != c.answer {
+		c.mistake = "Wrong, try again"
+		if c.kind == config.ChallengePhrase {
+			c.newPhrase(len(strings.Fields(c.prompt)))
+		} else {
+			c.input = ""
+		}
+		return
+	}
+
+	c.solved++
+	c.mistake = ""
+	if c.kind == config.ChallengeMath && c.solved < c.total {
+		c.nextProblem()
+	}
+}
+
+// pressKey advances a sequence challenge; a wrong key starts the sequence over
+func (c *dismissChallenge) pressKey(key string) {
+	if _, isSequenceKey := challengeKeySymbols[key]; !isSequenceKey {
+		return
+	}
+	if key != c.sequence[c.solved] {
+		c.solved = 0
+		c.mistake = "Wrong key, start over"
+		return
+	}
+	c.solved++
+	c.mistake = ""
+}
+
+// stopActiveAlarms stops ringing alarms, opening the dismiss challenge of the first
+// alarm that requires one. It returns true if a challenge was opened.
+func (m Model) stopActiveAlarms() bool {
+	for alarmID, activeAlarm := range m.app.alarmManager.GetActiveAlarms() {
+		if activeAlarm.Alarm.Challenge != config.ChallengeNone {
+			m.app.challenge = newDismissChallenge(alarmID, activeAlarm.Alarm)
+			m.app.state = StateDismissChallenge
+			return true
+		}
+		m.app.alarmManager.StopAlarm(alarmID)
+	}
+	return false
+}
+
+// refuseQuit opens the dismiss challenge of an active alarm that requires one, since
+// quitting would get around it. It returns true if quitting is refused.
+func (m Model) refuseQuit() bool {
+	for alarmID, activeAlarm := range m.app.alarmManager.GetActiveAlarms() {
+		if activeAlarm.Alarm.Challenge == config.ChallengeNone {
+			continue
+		}
+		if m.app.state != StateDismissChallenge || m.app.challenge == nil {
+			m.app.challenge = newDismissChallenge(alarmID, activeAlarm.Alarm)
+			m.app.state = StateDismissChallenge
+		}
+		m.app.challenge.mistake = "Solve the challenge to dismiss the alarm before quitting"
+		return true
+	}
+	return false
+}
+
+// handleChallengeKey handles typing in the dismiss challenge; TAB snoozes the alarm
+func (m Model) handleChallengeKey(key string) (tea.Model, tea.Cmd) {
+	c := m.app.challenge
+
+	switch {
+	case c == nil || !m.app.alarmManager.IsAlarmActive(c.alarmID):
+		// The alarm stopped by itself in the meantime
+		m.leaveChallenge()
+	case key == "tab":
+		m.app.alarmManager.SnoozeAlarm(c.alarmID)
+		m.leaveChallenge()
+	case c.kind == config.ChallengeSequence:
+		c.pressKey(key)
+	case c.kind == config.ChallengeMath:
+		handleGenericInput(&c.input, key, 6, func(key string) bool { return key >= "0" && key <= "9" })
+	default:
+		handleGenericInput(&c.input, key, 128, pathInputValidator)
+	}
+
+	return m.finishChallenge()
+}
+
+// submitChallenge checks the answer typed so far (ENTER)
+func (m Model) submitChallenge() (tea.Model, tea.Cmd) {
+	if c := m.app.challenge; c != nil && c.kind != config.ChallengeSequence {
+		c.submit()
+	}
+	return m.finishChallenge()
+}
+
+// finishChallenge stops the alarm once its challenge is passed and moves on to
+// further ringing alarms
+func (m Model) finishChallenge() (tea.Model, tea.Cmd) {
+	c := m.app.challenge
+	if c == nil || !c.done() {
+		return m, nil
+	}
+
+	m.app.alarmManager.StopAlarm(c.alarmID)
+	m.leaveChallenge()
+	m.stopActiveAlarms()
+	return m, nil
+}
+
+// leaveChallenge returns to the main clock, an unsolved alarm keeps ringing
+func (m Model) leaveChallenge() {
+	m.app.challenge = nil
+	m.app.state = StateMainClock
+	m.app.selectedMenu = menuAlarms
+}
+
+// Render the dismiss challenge
+func (m Model) renderDismissChallenge() string {
+	var content strings.Builder
+
+	c := m.app.challenge
+	if c == nil {
+		return m.renderMainClock()
+	}
+
+	name := fmt.Sprintf("ALARM %d", c.alarmID)
+	if a := m.app.config.FindAlarm(c.alarmID); a != nil {
+		name = a.Name()
+	}
+	content.WriteString(m.app.titleStyle.Render(fmt.Sprintf("🧠 STOP %s", name)))
+	content.WriteString("\n\n")
+
+	inputStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1)
+
+	switch c.kind {
+	case config.ChallengeMath:
+		content.WriteString(fmt.Sprintf("Solve problem %d of %d:\n\n", c.solved+1, c.total))
+		content.WriteString(fmt.Sprintf("   %s = ", c.prompt))
+		content.WriteString(inputStyle.Render(fmt.Sprintf(" %s_ ", c.input)))
+	case config.ChallengePhrase:
+		content.WriteString("Type this phrase exactly:\n\n")
+		content.WriteString(fmt.Sprintf("   %s\n\n", c.prompt))
+		content.WriteString(inputStyle.Render(fmt.Sprintf(" %s_ ", c.input)))
+	default:
+		content.WriteString("Press these keys in order:\n\n   ")
+		for i, key := range c.sequence {
+			symbol := challengeKeySymbols[key]
+			if i < c.solved {
+				content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" %s ", symbol)))
+			} else {
+				content.WriteString(fmt.Sprintf(" %s ", symbol))
+			}
+		}
+	}
+	content.WriteString("\n\n")
+
+	if c.mistake != "" {
+		content.WriteString(m.app.errorStyle.Render(c.mistake))
+		content.WriteString("\n\n")
+	}
+
+	instructions := "ENTER to check  •  TAB snooze  •  ESC back (alarm keeps ringing)"
+	if c.kind == config.ChallengeSequence {
+		instructions = "← ↑ → ↓ to answer  •  TAB snooze  •  ESC back (alarm keeps ringing)"
+	}
+	content.WriteString(m.app.instructionStyle.Render(instructions))
+
+	return content.String()
+}
@@ -35,6 +35,27 @@ const (
 	StateSleepCustomPath
 	StateBuzzerDirInput
 	StateSootherDirInput
+	StateAlarmList
+	StateAlarmLabel
+	StateDateInput
+	StateRecurrenceInput
+	StateAlarmSnoozeInput
+	StateAlarmStages
+	StateAlarmStageEdit
+	StateAlarmStageValue
+	StateHolidays
+	StateHolidayDateInput
+	StateHolidayFileInput
+	StateActivityFileInput
+	StateDismissChallenge
+	StateAlarmTimezoneInput
+	StateSecondTimezoneInput
+	StateTimers
+	StateTimerInput
+	StateSessionSetup
+	StateSession
+	StateIntervalInput
+	StateStopwatch
 	//StateShowNavigationBar
 	//StateShowSettingsBar
 	//StateShowInactiveItems
@@ -51,13 +72,20 @@ type App struct {
 	audioPlayer  *audio.Player
 
 	// UI state
-	state           AppState
-	selectedMenu    int
-	editingAlarm    int // 1 or 2
-	timeInput       string
-	customPathInput string
-	availableTones  []string
-	availableFonts  []string
+	state            AppState
+	selectedMenu     int
+	editingAlarm     int // ID of the alarm being edited
+	editingStage     int // Index of the alarm stage being edited
+	timeInput        string
+	dateInput        string
+	dateField        alarmEditItem // Which alarm date is being edited
+	inputError       string        // Validation error shown on input screens
+	challenge        *dismissChallenge
+	stopwatchNote    string // Result of the last lap export
+	stopwatchTicking bool   // A fast stopwatch redraw is scheduled
+	customPathInput  string
+	availableTones   []string
+	availableFonts   []string
 
 	// Styles with modern hacker colors
 	titleStyle       lipgloss.Style
@@ -68,6 +96,24 @@ type App struct {
 	instructionStyle lipgloss.Style
 }
 
+// Main menu entries shown in the bottom menu bar
+const (
+	menuSettings = iota
+	menuAlarms
+	menuSleep
+	menuTimers
+	menuFocus
+	menuStopwatch
+)
+
+var mainMenuItems = []string{"SETTINGS", "ALARMS", "SLEEP", "TIMERS", "FOCUS", "STOPWATCH"}
+
+// sleepFadeOptions are the selectable sleep timer fade-out lengths in minutes
+var sleepFadeOptions = []int{0, 1, 2, 5, 10, 15, 30}
+
+// statusItemsPerRow limits how many alarms are shown side by side on the main clock
+const statusItemsPerRow = 3
+
 // Model represents the bubbletea model
 type Model struct {
 	app *App
@@ -290,7 +336,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return TickMsg(t)
 		})
 
+	case stopwatchTickMsg:
+		return m.handleStopwatchTick()
+
+	case alarmDisabledMsg:
+		if a := m.app.config.FindAlarm(msg.alarmID); a != nil && a.Enabled {
+			a.Enabled = false
+			m.saveAlarms()
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		// Any key press means the sleeper is awake, which ends a wake window early. Keys
+		// that stop or snooze the alarm are not, they would bring it to full volume first.
+		if !m.isAlarmKey(msg.String()) {
+			m.app.alarmManager.NotifyActivity()
+		}
+
+		// Text input screens consume every key except the ones that leave them
+		if m.isInTextInputState() {
+			switch msg.String() {
+			case "ctrl+c", "esc", "enter":
+			default:
+				return m.handleTextInput(msg.String())
+			}
+		}
 
 		//// SLEEP TIMER
 		//// Handle sleep timer stop with 's' key or SPACE when active
@@ -318,13 +388,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 
 		case "s", " ":
-			// Todo: Test
-			// stop sleep timer
-			if m.app.timerManager.IsTimerActive(timer.TypeSleep) && m.app.state == StateMainClock {
-				m.app.timerManager.StopTimer(timer.TypeSleep)
-				return m, nil
-			}
-
 			// Todo: Test
 			// snooze
 			activeAlarms := m.app.alarmManager.GetActiveAlarms()
@@ -336,6 +399,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "ctrl+c", "q":
+			if m.refuseQuit() {
+				return m, nil
+			}
 			// IMPORTANT: Save config before quitting to fix alarm settings saving issue
 			if err := m.app.config.Save(); err != nil {
 				// Log error but don't prevent quit
@@ -347,28 +413,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case StateSettings:
 				m.app.state = StateMainClock
 				m.app.selectedMenu = 0
-			case StateAlarmEdit:
+			case StateAlarmList:
 				m.app.state = StateMainClock
-				m.app.selectedMenu = m.app.editingAlarm - 1
+				m.app.selectedMenu = menuAlarms
+				m.app.inputError = ""
+			case StateAlarmEdit:
+				m.app.state = StateAlarmList
+				m.app.selectedMenu = m.alarmIndex(m.app.editingAlarm)
 			case StateSleepEdit:
-				// Start sleep timer when leaving settings if duration > 0
-				if m.app.config.SleepTimer.Duration > 0 {
-					m.app.timerManager.StartSleepTimer(m.app.config.SleepTimer.Duration)
+				// Start sleep timer when leaving settings if a duration is set, a running one keeps its time
+				if m.app.config.SleepTimer.Enabled() && !m.app.timerManager.IsTimerActive(timer.TypeSleep) {
+					m.startSleepTimer()
 				}
 				m.app.state = StateMainClock
-				m.app.selectedMenu = 3 // Sleep menu index
-			case StateTimeInput, StateAlarmDays, StateAlarmVolume, StateAlarmToneSelect, StateAlarmCustomPath:
+				m.app.selectedMenu = menuSleep
+			case StateTimeInput, StateAlarmDays, StateAlarmVolume, StateAlarmToneSelect, StateAlarmCustomPath, StateAlarmLabel:
 				m.app.state = StateAlarmEdit
 				m.app.selectedMenu = 0
 				m.app.customPathInput = "" // Clear input on cancel
+			case StateAlarmStages:
+				m.app.state = StateAlarmEdit
+				m.selectAlarmEditItem(alarmItemStages)
+			case StateAlarmStageEdit:
+				m.app.state = StateAlarmStages
+				m.app.selectedMenu = m.app.editingStage
+			case StateAlarmStageValue:
+				m.app.state = StateAlarmStageEdit
+				m.app.selectedMenu = stageItemValue
+				m.app.customPathInput = "" // Clear input on cancel
+			case StateAlarmSnoozeInput:
+				m.app.state = StateAlarmEdit
+				m.selectAlarmEditItem(alarmItemSnooze)
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateRecurrenceInput:
+				m.app.state = StateAlarmEdit
+				m.selectAlarmEditItem(alarmItemRecurrence)
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateDateInput:
+				m.app.state = StateAlarmEdit
+				m.selectAlarmEditItem(m.app.dateField)
+				m.app.dateInput = "" // Clear input on cancel
+				m.app.inputError = ""
 			case StateSleepDuration, StateSleepVolume, StateSleepSoundSelect, StateSleepCustomPath:
-				// Start sleep timer when leaving duration settings if duration > 0
-				if m.app.state == StateSleepDuration && m.app.config.SleepTimer.Duration > 0 {
-					m.app.timerManager.StartSleepTimer(m.app.config.SleepTimer.Duration)
-				}
 				m.app.state = StateSleepEdit
 				m.app.selectedMenu = 0
 				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateHolidays:
+				m.app.state = StateSettings
+				m.app.selectedMenu = 11
+			case StateDismissChallenge:
+				// The alarm keeps ringing, snooze stays available on the main clock
+				m.leaveChallenge()
+			case StateActivityFileInput:
+				m.app.state = StateSettings
+				m.app.selectedMenu = 12
+				m.app.customPathInput = "" // Clear input on cancel
+			case StateSecondTimezoneInput:
+				m.app.state = StateSettings
+				m.app.selectedMenu = 13
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateAlarmTimezoneInput:
+				m.app.state = StateAlarmEdit
+				m.selectAlarmEditItem(alarmItemTimezone)
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateTimers:
+				m.app.state = StateMainClock
+				m.app.selectedMenu = menuTimers
+			case StateTimerInput:
+				m.app.state = StateTimers
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateSessionSetup, StateSession:
+				// A running session continues in the background
+				m.app.state = StateMainClock
+				m.app.selectedMenu = menuFocus
+			case StateStopwatch:
+				// A running stopwatch continues in the background
+				m.app.state = StateMainClock
+				m.app.selectedMenu = menuStopwatch
+				m.app.stopwatchNote = ""
+				m.app.inputError = ""
+			case StateIntervalInput:
+				m.app.state = StateSessionSetup
+				m.app.selectedMenu = m.sessionItemBack()
+				m.app.customPathInput = "" // Clear input on cancel
+				m.app.inputError = ""
+			case StateHolidayDateInput, StateHolidayFileInput:
+				m.app.state = StateHolidays
+				m.app.dateInput = "" // Clear input on cancel
+				m.app.customPathInput = ""
+				m.app.inputError = ""
 			case StateBuzzerDirInput:
 				m.app.state = StateSettings
 				m.app.selectedMenu = 3
@@ -394,17 +533,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleDown()
 
 		case "left", "h":
-			// Don't handle navigation if we're in a path input state
-			if m.isInPathInputState() {
-				return m.handleCustomPathInput(msg.String())
-			}
 			return m.handleLeft()
 
 		case "right", "l":
-			// Don't handle navigation if we're in a path input state
-			if m.isInPathInputState() {
-				return m.handleCustomPathInput(msg.String())
-			}
 			return m.handleRight()
 
 		//case "t":
@@ -432,10 +563,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		//	}
 
 		default:
-			if m.app.state == StateTimeInput {
-				return m.handleTimeInput(msg.String())
-			} else if m.isInPathInputState() {
-				return m.handleCustomPathInput(msg.String())
+			switch m.app.state {
+			case StateMainClock:
+				return m.handleMainClockKey(msg.String())
+			case StateAlarmList:
+				return m.handleAlarmListKey(msg.String())
+			case StateHolidays:
+				return m.handleHolidaysKey(msg.String())
+			case StateAlarmStages:
+				return m.handleStagesKey(msg.String())
+			case StateTimers:
+				return m.handleTimersKey(msg.String())
+			case StateSessionSetup:
+				return m.handleSessionSetupKey(msg.String())
+			case StateSession:
+				return m.handleSessionKey(msg.String())
+			case StateStopwatch:
+				return m.handleStopwatchKey(msg.String())
 			}
 		}
 	}
@@ -476,6 +620,48 @@ func (m Model) View() string {
 		return m.renderBuzzerDirInput()
 	case StateSootherDirInput:
 		return m.renderSootherDirInput()
+	case StateAlarmList:
+		return m.renderAlarmList()
+	case StateAlarmLabel:
+		return m.renderAlarmLabel()
+	case StateDateInput:
+		return m.renderDateInput()
+	case StateRecurrenceInput:
+		return m.renderRecurrenceInput()
+	case StateAlarmSnoozeInput:
+		return m.renderSnoozeInput()
+	case StateAlarmStages:
+		return m.renderAlarmStages()
+	case StateAlarmStageEdit:
+		return m.renderAlarmStageEdit()
+	case StateAlarmStageValue:
+		return m.renderAlarmStageValue()
+	case StateHolidays:
+		return m.renderHolidays()
+	case StateHolidayDateInput:
+		return m.renderHolidayDateInput()
+	case StateHolidayFileInput:
+		return m.renderHolidayFileInput()
+	case StateActivityFileInput:
+		return m.renderActivityFileInput()
+	case StateDismissChallenge:
+		return m.renderDismissChallenge()
+	case StateAlarmTimezoneInput:
+		return m.renderAlarmTimezoneInput()
+	case StateSecondTimezoneInput:
+		return m.renderSecondTimezoneInput()
+	case StateTimers:
+		return m.renderTimers()
+	case StateTimerInput:
+		return m.renderTimerInput()
+	case StateSessionSetup:
+		return m.renderSessionSetup()
+	case StateSession:
+		return m.renderSession()
+	case StateIntervalInput:
+		return m.renderIntervalInput()
+	case StateStopwatch:
+		return m.renderStopwatch()
 	default:
 		return m.renderMainClock()
 	}
@@ -500,17 +686,44 @@ func (m Model) renderMainClock() string {
 	content.WriteString(styledTime)
 	content.WriteString("\n\n")
 
+	// Add the second timezone clock
+	if secondClock := m.renderSecondClock(now); secondClock != "" {
+		content.WriteString(secondClock)
+		content.WriteString("\n\n")
+	}
+
 	// Add alarm status with colors
 	content.WriteString(m.renderAlarmStatus())
 	content.WriteString("\n\n")
 
+	// Add the running Pomodoro or interval session
+	if session := m.sessionStatusText(now); session != "" {
+		content.WriteString(session)
+		content.WriteString("\n\n")
+	}
+
+	// Add running kitchen timers
+	if countdowns := m.renderCountdownStatus(now); countdowns != "" {
+		content.WriteString(countdowns)
+		content.WriteString("\n\n")
+	}
+
+	// Add missed alarm notices
+	if missed := m.renderMissedAlarms(); missed != "" {
+		content.WriteString(missed)
+		content.WriteString("\n\n")
+	}
+
 	// Add simple bottom menu bar
 	content.WriteString(m.renderBottomMenu())
 	content.WriteString("\n\n")
 
 	// Add navigation instructions
 	if m.app.config.ShowNavigationBar == true {
-		instructions := "← → to navigate  •  ENTER to select  •  Q to quit"
+		instructions := "← → to navigate  •  ENTER to select  •  N skip next alarm  •  U undo skips  •  Q to quit"
+		if m.app.timerManager.IsTimerActive(timer.TypeSleep) {
+			instructions = "← → to navigate  •  ENTER to select  •  + sleep 5 min longer  •  P pause sleep timer  •  Q to quit"
+		}
 		content.WriteString(m.app.instructionStyle.Render(instructions))
 	}
 
@@ -523,72 +736,57 @@ func (m Model) renderAlarmStatus() string {
 
 	// Check for active alarms
 	activeAlarms := m.app.alarmManager.GetActiveAlarms()
+	skippedAlarms := m.app.alarmManager.GetSkipped()
+	now := time.Now()
 
 	// Regular alarm status display (enhanced to show active alarms)
-	// Alarm 1 status
-	alarm1Icon := "🔴"
-	color1 := "#666666"
-	_, isAlarm1Active := activeAlarms[1]
-
-	if isAlarm1Active {
-		alarm1Icon = "⏰"
-		color1 = "#FF0000"
-	} else if m.app.config.Alarm1.Enabled {
-		alarm1Icon = "🔔"
-		color1 = "#00FF00"
-	}
+	shown := 0
+	for i := range m.app.config.Alarms {
+		a := &m.app.config.Alarms[i]
 
-	alarm1Text := fmt.Sprintf("%s ALARM 1: %s", alarm1Icon, m.app.config.Alarm1.Time[:5])
-	if isAlarm1Active {
-		alarm1Text += " [ACTIVE]"
-	} else if m.app.config.Alarm1.Enabled {
-		activeDays := m.getActiveDaysString(m.app.config.Alarm1.Days)
-		alarm1Text += fmt.Sprintf(" [%s]", activeDays)
-	} else {
-		alarm1Text += " [OFF]"
-	}
+		// respect config
+		if m.app.config.ShowInactiveItems == false && !a.Enabled {
+			continue
+		}
 
-	// respect config
-	if m.app.config.ShowInactiveItems == true || m.app.config.Alarm1.Enabled {
-		status.WriteString(lipgloss.NewStyle().
-			Foreground(lipgloss.Color(color1)).
-			Bold(isAlarm1Active).
-			Render(alarm1Text))
+		alarmIcon := "🔴"
+		color := "#666666"
+		activeAlarm, isActive := activeAlarms[a.ID]
 
-		status.WriteString("    ")
-	}
-
-	// Alarm 2 status
-	alarm2Icon := "🔴"
-	color2 := "#666666"
-	_, isAlarm2Active := activeAlarms[2]
-
-	if isAlarm2Active {
-		alarm2Icon = "⏰"
-		color2 = "#FF0000"
-	} else if m.app.config.Alarm2.Enabled {
-		alarm2Icon = "🔔"
-		color2 = "#00FF00"
-	}
+		if isActive {
+			alarmIcon = "⏰"
+			color = "#FF0000"
+		} else if a.Enabled {
+			alarmIcon = "🔔"
+			color = "#00FF00"
+		}
 
-	alarm2Text := fmt.Sprintf("%s ALARM 2: %s", alarm2Icon, m.app.config.Alarm2.Time[:5])
-	if isAlarm2Active {
-		alarm2Text += " [ACTIVE]"
-	} else if m.app.config.Alarm2.Enabled {
-		activeDays := m.getActiveDaysString(m.app.config.Alarm2.Days)
-		alarm2Text += fmt.Sprintf(" [%s]", activeDays)
-	} else {
-		alarm2Text += " [OFF]"
-	}
+		alarmText := fmt.Sprintf("%s %s: %s", alarmIcon, a.Name(), alarmTimeText(a))
+		if isActive {
+			alarmText += fmt.Sprintf(" [%s]", activeAlarmStatus(activeAlarm))
+		} else if a.Enabled {
+			alarmText += fmt.Sprintf(" [%s]", m.getScheduleString(a))
+			skipped, exists := skippedAlarms[a.ID]
+			alarmText += holidaySkipText(skipped, exists, now)
+			if a.SkipPending(now) {
+				alarmText += fmt.Sprintf(" skipping %s", a.SkipUntil.Format("Mon 15:04"))
+			}
+		} else {
+			alarmText += " [OFF]"
+		}
 
-	// respect config
-	if m.app.config.ShowInactiveItems == true || m.app.config.Alarm2.Enabled {
 		status.WriteString(lipgloss.NewStyle().
-			Foreground(lipgloss.Color(color2)).
-			Bold(isAlarm2Active).
-			Render(alarm2Text))
-
-		status.WriteString("    ")
+			Foreground(lipgloss.Color(color)).
+			Bold(isActive).
+			Render(alarmText))
+
+		// Wrap long alarm lists onto several rows
+		shown++
+		if shown%statusItemsPerRow == 0 {
+			status.WriteString("\n")
+		} else {
+			status.WriteString("    ")
+		}
 	}
 
 	// Sleep Timer status (always shown)
@@ -597,17 +795,23 @@ func (m Model) renderAlarmStatus() string {
 	var sleepText string
 
 	// Check if sleep timer is actually running
-	if m.app.timerManager.IsTimerActive(timer.TypeSleep) {
+	if sleepTimer, active := m.app.timerManager.GetTimer(timer.TypeSleep); active {
 		sleepIcon = "🌙"
 		colorSleep = "#00FF00"
-		remaining := m.app.timerManager.GetTimeRemaining(timer.TypeSleep)
+		remaining := sleepTimer.TimeLeft(time.Now())
 		sleepText = fmt.Sprintf("%s SLEEP: %s", sleepIcon, timer.FormatTimeRemaining(remaining))
-	} else if m.app.config.SleepTimer.Duration > 0 {
+		if sleepTimer.UntilEnd {
+			sleepText = fmt.Sprintf("%s SLEEP: until end of file", sleepIcon)
+		} else if sleepTimer.Paused {
+			colorSleep = "#FFFF00"
+			sleepText += " PAUSED"
+		}
+	} else if m.app.config.SleepTimer.Enabled() {
 		sleepIcon = "🌙"
 		colorSleep = "#FFFF00" // Yellow for ready but not running
-		sleepText = fmt.Sprintf("%s SLEEP: %dm [READY]", sleepIcon, m.app.config.SleepTimer.Duration)
+		sleepText = fmt.Sprintf("%s SLEEP: %s [READY]", sleepIcon, m.app.config.SleepTimer.DurationText())
 	} else {
-		sleepText = fmt.Sprintf("%s SLEEP: %dm [OFF]", sleepIcon, m.app.config.SleepTimer.Duration)
+		sleepText = fmt.Sprintf("%s SLEEP: [OFF]", sleepIcon)
 	}
 
 	// respect config
@@ -642,11 +846,9 @@ func (m Model) getActiveDaysString(days []bool) string {
 // Render simple bottom menu (no complex arrows or styles)
 func (m Model) renderBottomMenu() string {
 	// Always show normal menu, even when alarms are active
-	menuItems := []string{"SETTINGS", "ALARM 1", "ALARM 2", "SLEEP"}
-
 	var rendered []string
 
-	for i, item := range menuItems {
+	for i, item := range mainMenuItems {
 		style := m.app.menuStyle
 		if i == m.app.selectedMenu && m.app.state == StateMainClock {
 			style = m.app.selectedStyle
@@ -667,34 +869,29 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		activeAlarms := m.app.alarmManager.GetActiveAlarms()
 
 		switch m.app.selectedMenu {
-		case 0: // Settings
+		case menuSettings:
 			m.app.state = StateSettings
 			m.app.selectedMenu = 0
-		case 1: // Alarm 1
-			// If Alarm 1 is active, stop it; otherwise go to edit screen
-			if _, isActive := activeAlarms[1]; isActive {
-				m.app.alarmManager.StopAlarm(1)
+		case menuAlarms:
+			// If alarms are ringing or snoozed, stop them (alarms with a dismiss
+			// challenge only once it is solved); otherwise go to the alarm list
+			if len(activeAlarms) > 0 {
+				m.stopActiveAlarms()
 			} else {
-				m.app.state = StateAlarmEdit
-				m.app.editingAlarm = 1
+				m.app.state = StateAlarmList
 				m.app.selectedMenu = 0
 			}
-		case 2: // Alarm 2
-			// If Alarm 2 is active, stop it; otherwise go to edit screen
-			if _, isActive := activeAlarms[2]; isActive {
-				m.app.alarmManager.StopAlarm(2)
-			} else {
-				m.app.state = StateAlarmEdit
-				m.app.editingAlarm = 2
-				m.app.selectedMenu = 0
-			}
-		case 3: // Sleep Timer
-			// Stop any active sleep timer when entering the menu
-			if m.app.timerManager.IsTimerActive(timer.TypeSleep) {
-				m.app.timerManager.StopTimer(timer.TypeSleep)
-			}
+		case menuSleep:
+			// A running sleep timer keeps running, a new duration replaces it
 			m.app.state = StateSleepEdit
 			m.app.selectedMenu = 0
+		case menuTimers:
+			m.app.state = StateTimers
+			m.app.selectedMenu = 0
+		case menuFocus:
+			m.openSession()
+		case menuStopwatch:
+			return m, m.openStopwatch()
 		}
 	case StateSettings:
 		switch m.app.selectedMenu {
@@ -730,46 +927,108 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		case 7: // Show Inactive Items
 			m.app.config.ShowInactiveItems = !m.app.config.ShowInactiveItems
 			m.app.config.Save()
-		case 8: // Show Alarm 2
-			m.app.config.ShowAlarm2 = !m.app.config.ShowAlarm2
-			m.app.config.Save()
-		case 9: // Show Sleep Timer
+		case 8: // Show Sleep Timer
 			m.app.config.ShowSleepTimer = !m.app.config.ShowSleepTimer
 			m.app.config.Save()
-		case 10: // Back
+		case 9: // Missed alarm grace window
+			m.app.config.MissedAlarmGraceMinutes = cycleOption(m.app.config.MissedAlarmGraceMinutes, missedGraceOptions)
+			m.saveSettings()
+		case 10: // Missed alarm action
+			if m.app.config.MissedAlarmAction == config.MissedAlarmRing {
+				m.app.config.MissedAlarmAction = config.MissedAlarmNotify
+			} else {
+				m.app.config.MissedAlarmAction = config.MissedAlarmRing
+			}
+			m.saveSettings()
+		case 11: // Holidays
+			m.app.state = StateHolidays
+			m.app.selectedMenu = 0
+		case 12: // Activity file
+			m.app.state = StateActivityFileInput
+			m.app.customPathInput = m.app.config.ActivityFile
+		case 13: // Second clock
+			m.app.state = StateSecondTimezoneInput
+			m.app.customPathInput = m.app.config.SecondTimezone
+			m.app.inputError = ""
+		case 14: // Back
 			m.app.state = StateMainClock
 			m.app.selectedMenu = 0
 		}
-	case StateAlarmEdit:
-		var a *config.Alarm
-		if m.app.editingAlarm == 1 {
-			a = &m.app.config.Alarm1
-		} else {
-			a = &m.app.config.Alarm2
-		}
-
-		maxOptions := 5 // Enabled, Time, Days, Volume, Source
-		if a.Source == config.SourceBuzzer {
-			maxOptions = 6 // Add Tone selection
-		} else if a.Source == config.SourceMP3 || a.Source == config.SourceRadio {
-			maxOptions = 6 // Add Custom path
+	case StateAlarmList:
+		if m.app.selectedMenu < len(m.app.config.Alarms) {
+			m.app.editingAlarm = m.app.config.Alarms[m.app.selectedMenu].ID
+			m.app.state = StateAlarmEdit
+			m.app.selectedMenu = 0
+			m.app.inputError = ""
 		}
+	case StateAlarmEdit:
+		a := m.getCurrentAlarm()
 
-		switch m.app.selectedMenu {
-		case 0: // Toggle enabled
+		switch m.currentAlarmEditItem() {
+		case alarmItemEnabled:
 			a.Enabled = !a.Enabled
-			m.app.config.Save()
-		case 1: // Edit time
+			m.saveAlarms()
+		case alarmItemLabel:
+			m.app.state = StateAlarmLabel
+			m.app.customPathInput = a.Label
+		case alarmItemTime:
 			m.app.state = StateTimeInput
 			// Pre-fill with current a time
 			// Extract HH:MM from the time string (remove seconds if present)
 			m.app.timeInput = a.Time[:5]
-		case 2: // Edit days
+		case alarmItemWakeWindow:
+			a.WakeWindowMinutes = cycleOption(a.WakeWindowMinutes, wakeWindowOptions)
+			m.saveAlarms()
+		case alarmItemDays:
 			m.app.state = StateAlarmDays
 			m.app.selectedMenu = 0
-		case 3: // Edit volume
+		case alarmItemHolidays:
+			a.RespectHolidays = !a.RespectHolidays
+			m.saveAlarms()
+		case alarmItemChallenge:
+			cycleChallenge(a)
+			m.saveAlarms()
+		case alarmItemChallengeCount:
+			a.ChallengeCount = cycleOption(challengeCount(a), challengeCountOptions)
+			m.saveAlarms()
+		case alarmItemStages:
+			m.app.state = StateAlarmStages
+			m.app.selectedMenu = 0
+		case alarmItemSnooze:
+			m.app.state = StateAlarmSnoozeInput
+			m.app.customPathInput = snoozeText(a)
+			m.app.inputError = ""
+		case alarmItemMaxRing:
+			a.MaxRingMinutes = cycleOption(ringMinutes(a), maxRingOptions)
+			m.saveAlarms()
+		case alarmItemNoResponse:
+			cycleNoResponseAction(a)
+			m.saveAlarms()
+		case alarmItemMaxSnoozes:
+			a.MaxSnoozes = cycleOption(a.MaxSnoozes, maxSnoozeOptions)
+			m.saveAlarms()
+		case alarmItemSkip:
+			if a.SkipPending(time.Now()) {
+				m.clearSkip(a)
+			} else {
+				m.skipNext(a)
+			}
+		case alarmItemTimezone:
+			m.app.state = StateAlarmTimezoneInput
+			m.app.customPathInput = a.Timezone
+			m.app.inputError = ""
+		case alarmItemRecurrence:
+			m.app.state = StateRecurrenceInput
+			m.app.customPathInput = a.Recurrence
+			m.app.inputError = ""
+		case alarmItemDate, alarmItemStartDate, alarmItemEndDate:
+			m.app.state = StateDateInput
+			m.app.dateField = m.currentAlarmEditItem()
+			m.app.dateInput = *alarmDateField(a, m.app.dateField)
+			m.app.inputError = ""
+		case alarmItemVolume:
 			m.app.state = StateAlarmVolume
-		case 4: // Change source
+		case alarmItemSource:
 			sources := []config.AlarmSource{config.SourceBuzzer, config.SourceMP3, config.SourceRadio}
 			currentIndex := 0
 			for i, source := range sources {
@@ -781,8 +1040,8 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			a.Source = sources[(currentIndex+1)%len(sources)]
 			// Reset source value when changing source
 			a.AlarmSourceValue = ""
-			m.app.config.Save()
-		case 5: // Source-specific options
+			m.saveAlarms()
+		case alarmItemSourceValue:
 			if a.Source == config.SourceBuzzer {
 				m.app.state = StateAlarmToneSelect
 				m.app.selectedMenu = 0
@@ -790,27 +1049,30 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 				m.app.state = StateAlarmCustomPath
 				m.app.customPathInput = a.AlarmSourceValue
 			}
-		default: // Back
-			if m.app.selectedMenu >= maxOptions {
-				m.app.state = StateMainClock
-				m.app.selectedMenu = m.app.editingAlarm - 1
-			}
+		case alarmItemBack:
+			m.app.state = StateAlarmList
+			m.app.selectedMenu = m.alarmIndex(m.app.editingAlarm)
 		}
 	case StateSleepEdit:
 		sleepTimer := &m.app.config.SleepTimer
-		maxOptions := 3 // Duration, Volume, Source
+		maxOptions := 4 // Duration, Volume, Fade Out, Source
 		if sleepTimer.Source == config.SourceSoother {
-			maxOptions = 4 // Add Sound selection
+			maxOptions = 5 // Add Sound selection
 		} else if sleepTimer.Source == config.SourceMP3 || sleepTimer.Source == config.SourceRadio {
-			maxOptions = 4 // Add Custom path
+			maxOptions = 5 // Add Custom path
 		}
 
 		switch m.app.selectedMenu {
-		case 0: // Edit duration with slider
+		case 0: // Type a duration
 			m.app.state = StateSleepDuration
+			m.app.customPathInput = ""
+			m.app.inputError = ""
 		case 1: // Volume
 			m.app.state = StateSleepVolume
-		case 2: // Change source
+		case 2: // Fade out
+			sleepTimer.FadeMinutes = cycleOption(sleepTimer.FadeMinutes, sleepFadeOptions)
+			m.app.config.Save()
+		case 3: // Change source
 			sources := []config.AlarmSource{config.SourceSoother, config.SourceMP3, config.SourceRadio}
 			currentIndex := 0
 			for i, source := range sources {
@@ -823,7 +1085,7 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			// Reset source value when changing source
 			sleepTimer.AlarmSourceValue = ""
 			m.app.config.Save()
-		case 3: // Source-specific options
+		case 4: // Source-specific options
 			if sleepTimer.Source == config.SourceSoother {
 				m.app.state = StateSleepSoundSelect
 				m.app.selectedMenu = 0
@@ -834,58 +1096,115 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		default: // Back
 			if m.app.selectedMenu >= maxOptions {
 				m.app.state = StateMainClock
-				m.app.selectedMenu = 3 // Sleep menu index
+				m.app.selectedMenu = menuSleep
 			}
 		}
 	case StateTimeInput:
 		// Process time input and save - ENTER leaves time set menu as requested
 		if m.parseAndSetTime() {
 			m.app.state = StateAlarmEdit
-			m.app.config.Save()
+			m.saveAlarms()
 		}
 	case StateAlarmDays:
 		// Toggle day selection
-		var a *config.Alarm
-		if m.app.editingAlarm == 1 {
-			a = &m.app.config.Alarm1
-		} else {
-			a = &m.app.config.Alarm2
-		}
+		a := m.getCurrentAlarm()
 		if m.app.selectedMenu < 7 {
 			a.Days[m.app.selectedMenu] = !a.Days[m.app.selectedMenu]
-			m.app.config.Save()
+			m.saveAlarms()
 		}
 	case StateAlarmVolume:
 		// Volume handled by left/right keys
 	case StateAlarmToneSelect:
 		// Select tone file
-		var a *config.Alarm
-		if m.app.editingAlarm == 1 {
-			a = &m.app.config.Alarm1
-		} else {
-			a = &m.app.config.Alarm2
-		}
+		a := m.getCurrentAlarm()
 		if m.app.selectedMenu < len(m.app.availableTones) {
 			a.AlarmSourceValue = m.app.config.BuzzerDir + "/" + m.app.availableTones[m.app.selectedMenu]
-			m.app.config.Save()
+			m.saveAlarms()
 			m.app.state = StateAlarmEdit
-			m.app.selectedMenu = 5
+			m.selectAlarmEditItem(alarmItemSourceValue)
 		}
 	case StateAlarmCustomPath:
 		// Save custom path
-		var a *config.Alarm
-		if m.app.editingAlarm == 1 {
-			a = &m.app.config.Alarm1
-		} else {
-			a = &m.app.config.Alarm2
-		}
+		a := m.getCurrentAlarm()
 		a.AlarmSourceValue = m.app.customPathInput
-		m.app.config.Save()
+		m.saveAlarms()
 		m.app.state = StateAlarmEdit
-		m.app.selectedMenu = 5
+		m.selectAlarmEditItem(alarmItemSourceValue)
+		m.app.customPathInput = ""
+	case StateDismissChallenge:
+		return m.submitChallenge()
+	case StateAlarmStages:
+		if m.app.selectedMenu < len(m.getCurrentAlarm().Stages) {
+			m.app.editingStage = m.app.selectedMenu
+			m.app.state = StateAlarmStageEdit
+			m.app.selectedMenu = 0
+		}
+	case StateAlarmStageEdit:
+		m.handleStageEditEnter()
+	case StateAlarmStageValue:
+		// Save stage MP3 path / radio URL
+		if stage := m.getCurrentStage(); stage != nil {
+			stage.AlarmSourceValue = strings.TrimSpace(m.app.customPathInput)
+			m.saveAlarms()
+		}
+		m.app.state = StateAlarmStageEdit
+		m.app.selectedMenu = stageItemValue
+		m.app.customPathInput = ""
+	case StateAlarmSnoozeInput:
+		// Validate and save the snooze override - an empty input uses the global value
+		if m.parseAndSetSnooze() {
+			m.saveAlarms()
+			m.app.state = StateAlarmEdit
+			m.selectAlarmEditItem(alarmItemSnooze)
+		}
+	case StateRecurrenceInput:
+		// Validate and save the recurrence - an empty input goes back to weekdays
+		if m.parseAndSetRecurrence() {
+			m.saveAlarms()
+			m.app.state = StateAlarmEdit
+			m.selectAlarmEditItem(alarmItemRecurrence)
+		}
+	case StateHolidayDateInput:
+		// Validate and add the exception date
+		if index, ok := m.addHolidayDate(); ok {
+			m.saveSettings()
+			m.app.state = StateHolidays
+			m.app.selectedMenu = index
+		}
+	case StateHolidayFileInput:
+		// Validate and add the calendar file
+		if index, ok := m.addHolidayFile(); ok {
+			m.saveSettings()
+			m.app.state = StateHolidays
+			m.app.selectedMenu = index
+		}
+	case StateDateInput:
+		// Validate and save the date - an empty input clears it
+		if m.parseAndSetDate() {
+			m.saveAlarms()
+			m.app.state = StateAlarmEdit
+			m.selectAlarmEditItem(m.app.dateField)
+		}
+	case StateAlarmLabel:
+		// Save alarm label
+		a := m.getCurrentAlarm()
+		a.Label = strings.TrimSpace(m.app.customPathInput)
+		m.saveAlarms()
+		m.app.state = StateAlarmEdit
+		m.selectAlarmEditItem(alarmItemLabel)
 		m.app.customPathInput = ""
 	case StateSleepDuration:
-		// Duration handled by left/right keys
+		// Validate the duration and (re)start the sleep timer with it, "off" stops it
+		if err := m.app.config.SleepTimer.SetDuration(m.app.customPathInput); err != nil {
+			m.app.inputError = err.Error()
+		} else {
+			m.app.config.Save()
+			m.startSleepTimer()
+			m.app.state = StateSleepEdit
+			m.app.selectedMenu = 0
+			m.app.customPathInput = ""
+			m.app.inputError = ""
+		}
 	case StateSleepVolume:
 		// Volume handled by left/right keys
 	case StateSleepSoundSelect:
@@ -918,24 +1237,113 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		m.app.state = StateSettings
 		m.app.selectedMenu = 4
 		m.app.customPathInput = ""
+	case StateAlarmTimezoneInput:
+		// Validate and save the alarm timezone - an empty input means local time
+		if name, ok := m.parseTimezoneInput(); ok {
+			m.getCurrentAlarm().SetTimezone(name)
+			m.saveAlarms()
+			m.app.state = StateAlarmEdit
+			m.selectAlarmEditItem(alarmItemTimezone)
+		}
+	case StateTimers:
+		if countdown, ok := m.selectedCountdown(); ok {
+			m.toggleCountdown(countdown)
+		}
+	case StateSessionSetup:
+		m.handleSessionSetupEnter()
+	case StateSession:
+		m.toggleSession()
+	case StateStopwatch:
+		return m, m.toggleStopwatch()
+	case StateIntervalInput:
+		// Validate and add the interval program
+		if index, ok := m.addIntervalInput(); ok {
+			m.app.config.Save()
+			m.app.state = StateSessionSetup
+			m.app.selectedMenu = index
+		}
+	case StateTimerInput:
+		// Validate and start the countdown
+		if index, ok := m.startCountdownInput(); ok {
+			m.app.state = StateTimers
+			m.app.selectedMenu = index
+		}
+	case StateSecondTimezoneInput:
+		// Validate and save the second clock timezone - an empty input hides it
+		if name, ok := m.parseTimezoneInput(); ok {
+			m.app.config.SecondTimezone = name
+			m.app.config.Save()
+			m.app.state = StateSettings
+			m.app.selectedMenu = 13
+		}
+	case StateActivityFileInput:
+		// Save wake window activity file - an empty input disables it
+		m.app.config.ActivityFile = strings.TrimSpace(m.app.customPathInput)
+		m.saveSettings()
+		m.app.state = StateSettings
+		m.app.selectedMenu = 12
+		m.app.customPathInput = ""
 	}
 
 	return m, nil
 }
 
+// isAlarmKey checks if the key stops or snoozes the active alarms, including the keys
+// typed into a dismiss challenge
+func (m Model) isAlarmKey(key string) bool {
+	if m.app.state == StateDismissChallenge {
+		return true
+	}
+	if m.app.state != StateMainClock || len(m.app.alarmManager.GetActiveAlarms()) == 0 {
+		return false
+	}
+	return key == "s" || key == " " || (key == "enter" && m.app.selectedMenu == menuAlarms)
+}
+
 // isInPathInputState checks if currently in a path input state
 func (m Model) isInPathInputState() bool {
 	return m.app.state == StateAlarmCustomPath ||
 		m.app.state == StateSleepCustomPath ||
 		m.app.state == StateBuzzerDirInput ||
-		m.app.state == StateSootherDirInput
+		m.app.state == StateSootherDirInput ||
+		m.app.state == StateAlarmLabel ||
+		m.app.state == StateRecurrenceInput ||
+		m.app.state == StateHolidayFileInput ||
+		m.app.state == StateAlarmStageValue ||
+		m.app.state == StateActivityFileInput ||
+		m.app.state == StateAlarmTimezoneInput ||
+		m.app.state == StateSecondTimezoneInput ||
+		m.app.state == StateTimerInput ||
+		m.app.state == StateSleepDuration ||
+		m.app.state == StateIntervalInput
+}
+
+// isInTextInputState checks if currently in any state that takes typed text
+func (m Model) isInTextInputState() bool {
+	return m.app.state == StateTimeInput || m.app.state == StateDateInput || m.app.state == StateHolidayDateInput ||
+		m.app.state == StateAlarmSnoozeInput || m.app.state == StateDismissChallenge || m.isInPathInputState()
+}
+
+// handleTextInput routes a key press to the input of the current state
+func (m Model) handleTextInput(key string) (tea.Model, tea.Cmd) {
+	switch m.app.state {
+	case StateTimeInput:
+		return m.handleTimeInput(key)
+	case StateDateInput, StateHolidayDateInput:
+		return m.handleDateInput(key)
+	case StateAlarmSnoozeInput:
+		return m.handleSnoozeInput(key)
+	case StateDismissChallenge:
+		return m.handleChallengeKey(key)
+	}
+	return m.handleCustomPathInput(key)
 }
 
 // toggleCurrentAlarm toggles the enabled state of the current alarm
 func (m Model) toggleCurrentAlarm() {
 	a := m.getCurrentAlarm()
 	a.Enabled = !a.Enabled
-	m.app.config.Save()
+	m.saveAlarms()
 }
 
 // NavigationConfig holds navigation bounds for different states
@@ -951,18 +1359,58 @@ func (m Model) getNavigationConfig() NavigationConfig {
 	case StateSettings:
 		return NavigationConfig{
 			MaxItems: 6, // Font, 24H, Seconds, Buzzer Dir,
-			// Soother Dir, Show Navigation, ..., Show Alarm 2, Show Sleep Timer, Back
+			// Soother Dir, Show Navigation, ..., Missed Grace, Missed Action, Holidays, Activity File, Second Clock, Back
 			CanNavigateUp:   m.app.selectedMenu > 0,
-			CanNavigateDown: m.app.selectedMenu < 10,
+			CanNavigateDown: m.app.selectedMenu < 14,
 		}
-	case StateAlarmEdit:
-		a := m.getCurrentAlarm()
-		maxOptions := 6 // Enabled, Time, Days, Volume, Source, Back
-		if a.Source == config.SourceBuzzer {
-			maxOptions = 7 // Add Tone selection
-		} else if a.Source == config.SourceMP3 || a.Source == config.SourceRadio {
-			maxOptions = 7 // Add Custom path
+	case StateAlarmStages:
+		stages := len(m.getCurrentAlarm().Stages)
+		return NavigationConfig{
+			MaxItems:        stages,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < stages-1,
 		}
+	case StateAlarmStageEdit:
+		return NavigationConfig{
+			MaxItems:        stageItemBack + 1,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < stageItemBack,
+		}
+	case StateSessionSetup:
+		return NavigationConfig{
+			MaxItems:        m.sessionItemBack() + 1,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < m.sessionItemBack(),
+		}
+	case StateStopwatch:
+		laps := len(m.app.timerManager.GetStopwatch().Laps)
+		return NavigationConfig{
+			MaxItems:        laps,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < laps-1,
+		}
+	case StateTimers:
+		countdowns := len(m.app.timerManager.GetCountdowns())
+		return NavigationConfig{
+			MaxItems:        countdowns,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < countdowns-1,
+		}
+	case StateHolidays:
+		entries := len(m.app.config.Holidays) + len(m.app.config.HolidayFiles)
+		return NavigationConfig{
+			MaxItems:        entries,
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < entries-1,
+		}
+	case StateAlarmList:
+		return NavigationConfig{
+			MaxItems:        len(m.app.config.Alarms),
+			CanNavigateUp:   m.app.selectedMenu > 0,
+			CanNavigateDown: m.app.selectedMenu < len(m.app.config.Alarms)-1,
+		}
+	case StateAlarmEdit:
+		maxOptions := len(alarmEditItems(m.getCurrentAlarm()))
 		return NavigationConfig{
 			MaxItems:        maxOptions,
 			CanNavigateUp:   m.app.selectedMenu > 0,
@@ -970,11 +1418,11 @@ func (m Model) getNavigationConfig() NavigationConfig {
 		}
 	case StateSleepEdit:
 		sleepTimer := &m.app.config.SleepTimer
-		maxItems := 5 // Duration, Volume, Source, Back
+		maxItems := 6 // Duration, Volume, Fade Out, Source, Back
 		if sleepTimer.Source == config.SourceSoother {
-			maxItems = 6 // Add Sound selection
+			maxItems = 7 // Add Sound selection
 		} else if sleepTimer.Source == config.SourceMP3 || sleepTimer.Source == config.SourceRadio {
-			maxItems = 6 // Add Custom path
+			maxItems = 7 // Add Custom path
 		}
 		return NavigationConfig{
 			MaxItems:        maxItems,
@@ -1007,10 +1455,12 @@ func (m Model) getNavigationConfig() NavigationConfig {
 
 // getCurrentAlarm returns pointer to current alarm being edited
 func (m Model) getCurrentAlarm() *config.Alarm {
-	if m.app.editingAlarm == 1 {
-		return &m.app.config.Alarm1
+	if a := m.app.config.FindAlarm(m.app.editingAlarm); a != nil {
+		return a
 	}
-	return &m.app.config.Alarm2
+	// The alarm was deleted; hand out a detached placeholder so rendering doesn't fail
+	placeholder := config.DefaultAlarm(m.app.editingAlarm, "00:00:00")
+	return &placeholder
 }
 
 // navigateUp handles upward navigation for all states
@@ -1056,7 +1506,7 @@ func adjustValue(current, min, max, step int) int {
 func (m Model) adjustAlarmVolume(delta int) {
 	a := m.getCurrentAlarm()
 	a.Volume = adjustValue(a.Volume, 0, 100, delta)
-	m.app.config.Save()
+	m.saveAlarms()
 }
 
 // adjustSleepVolume adjusts the sleep timer's volume
@@ -1066,11 +1516,15 @@ func (m Model) adjustSleepVolume(delta int) {
 	m.app.config.Save()
 }
 
-// adjustSleepDuration adjusts the sleep timer's duration
-func (m Model) adjustSleepDuration(delta int) {
+// startSleepTimer starts the sleep timer with the configured duration, stopping it if
+// no duration is set
+func (m Model) startSleepTimer() {
 	sleepTimer := &m.app.config.SleepTimer
-	sleepTimer.Duration = adjustValue(sleepTimer.Duration, 0, 120, delta)
-	m.app.config.Save()
+	if sleepTimer.UntilEnd {
+		m.app.timerManager.StartSleepTimerUntilEnd()
+		return
+	}
+	m.app.timerManager.StartSleepTimer(sleepTimer.Length(time.Now()))
 }
 
 func (m Model) handleLeft() (tea.Model, tea.Cmd) {
@@ -1081,8 +1535,8 @@ func (m Model) handleLeft() (tea.Model, tea.Cmd) {
 		}
 	case StateAlarmVolume:
 		m.adjustAlarmVolume(-5)
-	case StateSleepDuration:
-		m.adjustSleepDuration(-5)
+	case StateAlarmStageEdit:
+		m.adjustStageVolume(-5)
 	case StateSleepVolume:
 		m.adjustSleepVolume(-5)
 	}
@@ -1092,13 +1546,13 @@ func (m Model) handleLeft() (tea.Model, tea.Cmd) {
 func (m Model) handleRight() (tea.Model, tea.Cmd) {
 	switch m.app.state {
 	case StateMainClock:
-		if m.app.selectedMenu < 3 {
+		if m.app.selectedMenu < len(mainMenuItems)-1 {
 			m.app.selectedMenu++
 		}
 	case StateAlarmVolume:
 		m.adjustAlarmVolume(5)
-	case StateSleepDuration:
-		m.adjustSleepDuration(5)
+	case StateAlarmStageEdit:
+		m.adjustStageVolume(5)
 	case StateSleepVolume:
 		m.adjustSleepVolume(5)
 	}
@@ -1216,6 +1670,15 @@ func (m Model) renderMenuWithInstructions(title string, options []string, instru
 
 // Render settings menu (simple, no complex styling)
 func (m Model) renderSettings() string {
+	activityFile := m.app.config.ActivityFile
+	if activityFile == "" {
+		activityFile = "<not set>"
+	}
+	secondClock := m.app.config.SecondTimezone
+	if secondClock == "" {
+		secondClock = "OFF"
+	}
+
 	settings := []string{
 		fmt.Sprintf("Font: %s", m.app.config.FontName),
 		fmt.Sprintf("24H Format: %s", getBoolText(m.app.config.Hour24Format)),
@@ -1225,8 +1688,12 @@ func (m Model) renderSettings() string {
 		fmt.Sprintf("Show Navigation bar: %s", getBoolText(m.app.config.ShowNavigationBar)),
 		fmt.Sprintf("Show Settings bar: %s", getBoolText(m.app.config.ShowSettingsBar)),
 		fmt.Sprintf("Show Inactive Items: %s", getBoolText(m.app.config.ShowInactiveItems)),
-		fmt.Sprintf("Show Alarm 2: %s", getBoolText(m.app.config.ShowAlarm2)),
 		fmt.Sprintf("Show Sleep Timer: %s", getBoolText(m.app.config.ShowSleepTimer)),
+		fmt.Sprintf("Missed Alarm Grace: %s", formatMinutesOption(m.app.config.MissedAlarmGraceMinutes)),
+		fmt.Sprintf("Missed Alarm Action: %s", m.app.config.MissedAlarmAction),
+		fmt.Sprintf("Holidays: %d dates, %d calendars", len(m.app.config.Holidays), len(m.app.config.HolidayFiles)),
+		fmt.Sprintf("Activity File: %s", activityFile),
+		fmt.Sprintf("Second Clock: %s", secondClock),
 		"Back",
 	}
 
@@ -1241,39 +1708,13 @@ func (m Model) renderSettings() string {
 func (m Model) renderAlarmEdit() string {
 	a := m.getCurrentAlarm()
 
-	menuOptions := []string{
-		fmt.Sprintf("Enabled: %s", getBoolText(a.Enabled)),
-		fmt.Sprintf("Time: %s", a.Time[:5]),
-		fmt.Sprintf("Days: %s", m.getActiveDaysString(a.Days)),
-		fmt.Sprintf("Volume: %d%%", a.Volume),
-		fmt.Sprintf("Source: %s", a.Source),
+	var menuOptions []string
+	for _, item := range alarmEditItems(a) {
+		menuOptions = append(menuOptions, m.alarmEditItemText(a, item))
 	}
 
-	// Add source-specific options
-	if a.Source == config.SourceBuzzer {
-		toneFile := a.AlarmSourceValue
-		if toneFile == "" {
-			toneFile = "<not set>"
-		}
-		menuOptions = append(menuOptions, fmt.Sprintf("Tone: %s", toneFile))
-	} else if a.Source == config.SourceMP3 {
-		mp3Path := a.AlarmSourceValue
-		if mp3Path == "" {
-			mp3Path = "<not set>"
-		}
-		menuOptions = append(menuOptions, fmt.Sprintf("MP3 Path: %s", mp3Path))
-	} else if a.Source == config.SourceRadio {
-		radioURL := a.AlarmSourceValue
-		if radioURL == "" {
-			radioURL = "<not set>"
-		}
-		menuOptions = append(menuOptions, fmt.Sprintf("Radio URL: %s", radioURL))
-	}
-
-	menuOptions = append(menuOptions, "Back")
-
 	return m.renderMenuWithInstructions(
-		fmt.Sprintf("🔔 ALARM %d CONFIGURATION", m.app.editingAlarm),
+		fmt.Sprintf("🔔 %s CONFIGURATION", a.Name()),
 		menuOptions,
 		"↑↓ to navigate  •  ENTER to edit  •  ESC to return",
 	)
@@ -1284,8 +1725,9 @@ func (m Model) renderSleepEdit() string {
 	sleepTimer := &m.app.config.SleepTimer
 
 	menuOptions := []string{
-		fmt.Sprintf("Duration: %d minutes", sleepTimer.Duration),
+		fmt.Sprintf("Duration: %s", sleepTimer.DurationText()),
 		fmt.Sprintf("Volume: %d%%", sleepTimer.Volume),
+		fmt.Sprintf("Fade Out: %s", formatMinutesOption(sleepTimer.FadeMinutes)),
 		fmt.Sprintf("Source: %s", sleepTimer.Source),
 	}
 
@@ -1323,7 +1765,7 @@ func (m Model) renderSleepEdit() string {
 func (m Model) renderTimeInput() string {
 	var content strings.Builder
 
-	title := fmt.Sprintf("⏰ SET TIME FOR ALARM %d", m.app.editingAlarm)
+	title := fmt.Sprintf("⏰ SET TIME FOR %s", m.getCurrentAlarm().Name())
 	content.WriteString(m.app.titleStyle.Render(title))
 	content.WriteString("\n\n")
 
@@ -1351,16 +1793,11 @@ func (m Model) renderTimeInput() string {
 func (m Model) renderAlarmDays() string {
 	var content strings.Builder
 
-	title := fmt.Sprintf("📅 SELECT DAYS FOR ALARM %d", m.app.editingAlarm)
+	title := fmt.Sprintf("📅 SELECT DAYS FOR %s", m.getCurrentAlarm().Name())
 	content.WriteString(m.app.titleStyle.Render(title))
 	content.WriteString("\n\n")
 
-	var a *config.Alarm
-	if m.app.editingAlarm == 1 {
-		a = &m.app.config.Alarm1
-	} else {
-		a = &m.app.config.Alarm2
-	}
+	a := m.getCurrentAlarm()
 
 	days := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 
@@ -1388,16 +1825,11 @@ func (m Model) renderAlarmDays() string {
 func (m Model) renderAlarmVolume() string {
 	var content strings.Builder
 
-	title := fmt.Sprintf("🔊 VOLUME FOR ALARM %d", m.app.editingAlarm)
+	title := fmt.Sprintf("🔊 VOLUME FOR %s", m.getCurrentAlarm().Name())
 	content.WriteString(m.app.titleStyle.Render(title))
 	content.WriteString("\n\n")
 
-	var a *config.Alarm
-	if m.app.editingAlarm == 1 {
-		a = &m.app.config.Alarm1
-	} else {
-		a = &m.app.config.Alarm2
-	}
+	a := m.getCurrentAlarm()
 
 	content.WriteString(fmt.Sprintf("Current Volume: %d%%\n\n", a.Volume))
 
@@ -1416,7 +1848,7 @@ func (m Model) renderAlarmVolume() string {
 func (m Model) renderAlarmToneSelect() string {
 	var content strings.Builder
 
-	title := fmt.Sprintf("🎵 SELECT TONE FOR ALARM %d", m.app.editingAlarm)
+	title := fmt.Sprintf("🎵 SELECT TONE FOR %s", m.getCurrentAlarm().Name())
 	content.WriteString(m.app.titleStyle.Render(title))
 	content.WriteString("\n\n")
 
@@ -1439,20 +1871,15 @@ func (m Model) renderAlarmToneSelect() string {
 func (m Model) renderAlarmCustomPath() string {
 	var content strings.Builder
 
-	var a *config.Alarm
-	if m.app.editingAlarm == 1 {
-		a = &m.app.config.Alarm1
-	} else {
-		a = &m.app.config.Alarm2
-	}
+	a := m.getCurrentAlarm()
 
 	var title, prompt, example string
 	if a.Source == config.SourceMP3 {
-		title = fmt.Sprintf("🎵 MP3 PATH FOR ALARM %d", m.app.editingAlarm)
+		title = fmt.Sprintf("🎵 MP3 PATH FOR %s", a.Name())
 		prompt = "Enter MP3 file or directory path:"
 		example = "Examples: /home/user/music/a.mp3, /home/user/music/"
 	} else if a.Source == config.SourceRadio {
-		title = fmt.Sprintf("📻 RADIO URL FOR ALARM %d", m.app.editingAlarm)
+		title = fmt.Sprintf("📻 RADIO URL FOR %s", a.Name())
 		prompt = "Enter radio stream URL:"
 		example = "Examples: http://stream.com/radio.m3u, https://radio.com/stream"
 	}
@@ -1477,7 +1904,7 @@ func (m Model) renderAlarmCustomPath() string {
 	return content.String()
 }
 
-// Render sleep timer duration control screen
+// Render sleep timer duration input screen
 func (m Model) renderSleepDuration() string {
 	var content strings.Builder
 
@@ -1485,19 +1912,23 @@ func (m Model) renderSleepDuration() string {
 	content.WriteString(m.app.titleStyle.Render(title))
 	content.WriteString("\n\n")
 
-	sleepTimer := &m.app.config.SleepTimer
+	content.WriteString(fmt.Sprintf("Current Duration: %s\n\n", m.app.config.SleepTimer.DurationText()))
+	content.WriteString("Enter minutes, a duration, a time of day or until end:\n")
+	content.WriteString("Examples: 45, 2h30m, until 01:00, until end, off\n\n")
 
-	content.WriteString(fmt.Sprintf("Current Duration: %d minutes\n\n", sleepTimer.Duration))
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
 
-	// Duration bar (5-120 minutes range)
-	barWidth := 20
-	// Calculate percentage: (current - min) / (max - min)
-	percentage := float64(sleepTimer.Duration-5) / float64(120-5)
-	filledBars := int(percentage * float64(barWidth))
-	durationBar := strings.Repeat("█", filledBars) + strings.Repeat("░", barWidth-filledBars)
-	content.WriteString(fmt.Sprintf("[%s] %d min\n\n", durationBar, sleepTimer.Duration))
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
 
-	content.WriteString(m.app.instructionStyle.Render("←→ to adjust duration (5-120 min)  •  ESC to return"))
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type duration  •  ENTER to start the sleep timer  •  ESC to cancel"))
 
 	return content.String()
 }
@@ -1637,6 +2068,28 @@ func (m Model) renderSootherDirInput() string {
 	return content.String()
 }
 
+// Render wake window activity file input screen
+func (m Model) renderActivityFileInput() string {
+	var content strings.Builder
+
+	title := "👋 ACTIVITY FILE CONFIGURATION"
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString("Touching this file during a wake window makes the alarm ring fully (leave empty to disable):\n")
+	content.WriteString("Examples: /tmp/wecker-awake, /home/user/.awake\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type file path  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
+
 // Run starts the application
 func (app *App) Run() error {
 	_, err := app.program.Run()
@@ -1655,11 +2108,23 @@ func (app *App) GetProgram() *tea.Program {
 	return app.program
 }
 
+// alarmDisabledMsg disables a one-shot alarm that rang, on the UI goroutine which owns the alarm list
+type alarmDisabledMsg struct {
+	alarmID int
+}
+
+// DisableAlarm disables a configured alarm and saves the configuration
+func (app *App) DisableAlarm(alarmID int) {
+	if app.program != nil {
+		app.program.Send(alarmDisabledMsg{alarmID: alarmID})
+	}
+}
+
 // SetFocus sets the focus to the specified alarm when it becomes active
 func (app *App) SetFocus(alarmID int) {
 	if app.state == StateMainClock {
-		// Set selectedMenu to the alarm button (ALARM 1 = index 1, ALARM 2 = index 2)
-		app.selectedMenu = alarmID
+		// Set selectedMenu to the ALARMS button so ENTER stops the alarm
+		app.selectedMenu = menuAlarms
 	}
 }
 
@@ -0,0 +1,207 @@
+package display
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"wecker/alarm"
+	"wecker/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// holidayPreviewDays is how far ahead the holiday screen lists upcoming holidays
+const holidayPreviewDays = 60
+
+// handleHolidaysKey handles the add/delete shortcuts of the holiday screen
+func (m Model) handleHolidaysKey(key string) (tea.Model, tea.Cmd) {
+	cfg := m.app.config
+
+	switch key {
+	case "a": // Add exception date
+		m.app.state = StateHolidayDateInput
+		m.app.dateInput = time.Now().Format(config.DateLayout)
+		m.app.inputError = ""
+	case "f": // Add .ics calendar
+		m.app.state = StateHolidayFileInput
+		m.app.customPathInput = ""
+		m.app.inputError = ""
+	case "x", "delete": // Remove the selected entry
+		switch index := m.app.selectedMenu; {
+		case index < len(cfg.Holidays):
+			cfg.Holidays = slices.Delete(cfg.Holidays, index, index+1)
+		case index < len(cfg.Holidays)+len(cfg.HolidayFiles):
+			index -= len(cfg.Holidays)
+			cfg.HolidayFiles = slices.Delete(cfg.HolidayFiles, index, index+1)
+		default:
+			return m, nil
+		}
+		m.saveSettings()
+		if m.app.selectedMenu >= len(cfg.Holidays)+len(cfg.HolidayFiles) && m.app.selectedMenu > 0 {
+			m.app.selectedMenu--
+		}
+	}
+
+	return m, nil
+}
+
+// addHolidayDate validates the date input and adds it as exception date.
+// It returns the position of the new entry on the holiday screen.
+func (m Model) addHolidayDate() (int, bool) {
+	date := strings.TrimSpace(m.app.dateInput)
+	if _, err := config.ParseDate(date, time.Local); err != nil {
+		m.app.inputError = err.Error()
+		return 0, false
+	}
+
+	m.app.config.AddHoliday(date)
+	m.app.dateInput = ""
+	m.app.inputError = ""
+	return slices.Index(m.app.config.Holidays, date), true
+}
+
+// addHolidayFile validates the path input and adds it as holiday calendar.
+// It returns the position of the new entry on the holiday screen.
+func (m Model) addHolidayFile() (int, bool) {
+	path := strings.TrimSpace(m.app.customPathInput)
+	if !strings.EqualFold(filepath.Ext(path), ".ics") {
+		m.app.inputError = "not an .ics file"
+		return 0, false
+	}
+	if _, err := alarm.LoadHolidays(nil, []string{path}); err != nil {
+		m.app.inputError = err.Error()
+		return 0, false
+	}
+
+	cfg := m.app.config
+	if !slices.Contains(cfg.HolidayFiles, path) {
+		cfg.HolidayFiles = append(cfg.HolidayFiles, path)
+	}
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return len(cfg.Holidays) + slices.Index(cfg.HolidayFiles, path), true
+}
+
+// holidaySkipText returns the main clock note for an alarm whose occurrence today or
+// later falls on a holiday, or an empty string
+func holidaySkipText(skipped alarm.SkippedOccurrence, exists bool, now time.Time) string {
+	if !exists || skipped.Reason != alarm.SkipHoliday {
+		return ""
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case skipped.Occurrence.Before(today):
+		return ""
+	case skipped.Occurrence.Before(today.AddDate(0, 0, 1)):
+		return " skipped (holiday)"
+	default:
+		return fmt.Sprintf(" %s skipped (holiday)", skipped.Occurrence.Format("Mon"))
+	}
+}
+
+// Render the holiday screen with exception dates, calendars and upcoming holidays
+func (m Model) renderHolidays() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("🏖️ HOLIDAYS"))
+	content.WriteString("\n\n")
+	content.WriteString("Alarms with \"Skip Holidays\" stay quiet on these days\n\n")
+
+	cfg := m.app.config
+	var entries []string
+	for _, date := range cfg.Holidays {
+		entries = append(entries, fmt.Sprintf("Date: %s", date))
+	}
+	for _, path := range cfg.HolidayFiles {
+		entries = append(entries, fmt.Sprintf("Calendar: %s", path))
+	}
+
+	if len(entries) == 0 {
+		content.WriteString("   No holidays configured\n")
+	}
+	for i, entry := range entries {
+		if i == m.app.selectedMenu {
+			content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" > %s ", entry)))
+		} else {
+			content.WriteString(fmt.Sprintf("   %s", entry))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	calendar, err := m.app.alarmManager.Holidays()
+	if err != nil {
+		content.WriteString(m.app.errorStyle.Render(err.Error()))
+		content.WriteString("\n\n")
+	}
+
+	upcoming := calendar.Upcoming(time.Now(), holidayPreviewDays)
+	content.WriteString(fmt.Sprintf("Next %d days:\n", holidayPreviewDays))
+	if len(upcoming) == 0 {
+		content.WriteString("   none\n")
+	}
+	for _, holiday := range upcoming {
+		content.WriteString(fmt.Sprintf("   %s  %s\n", holiday.Date.Format("Mon 02 Jan 2006"), holiday.Name))
+	}
+	content.WriteString("\n")
+
+	content.WriteString(m.app.instructionStyle.Render("↑↓ to navigate  •  A add date  •  F add .ics file  •  X remove  •  ESC to return"))
+
+	return content.String()
+}
+
+// Render holiday date input screen
+func (m Model) renderHolidayDateInput() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("📅 ADD HOLIDAY"))
+	content.WriteString("\n\n")
+	content.WriteString("Day on which holiday-aware alarms stay quiet:\n")
+	content.WriteString("Format: YYYY-MM-DD, e.g. 2026-12-24\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.dateInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type numbers and -  •  ENTER to add  •  ESC to cancel"))
+
+	return content.String()
+}
+
+// Render holiday calendar file input screen
+func (m Model) renderHolidayFileInput() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("📂 ADD HOLIDAY CALENDAR"))
+	content.WriteString("\n\n")
+	content.WriteString("Enter the path of an .ics file with all-day holiday events:\n")
+	content.WriteString("Examples: holidays/de-by.ics, /home/user/vacation.ics\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type path  •  ENTER to add  •  ESC to cancel"))
+
+	return content.String()
+}
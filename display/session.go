@@ -0,0 +1,273 @@
+package display
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"wecker/config"
+	"wecker/timer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/common-nighthawk/go-figure"
+)
+
+// Session setup menu entries; the interval programs follow sessionItemCycles, Back comes last
+const (
+	sessionItemPomodoro = iota
+	sessionItemWork
+	sessionItemShortBreak
+	sessionItemLongBreak
+	sessionItemCycles
+	sessionItemIntervals
+)
+
+// sessionLogLines is how many session log entries the session view shows
+const sessionLogLines = 5
+
+// Selectable Pomodoro settings
+var (
+	workMinuteOptions       = []int{15, 20, 25, 30, 45, 50, 60, 90}
+	shortBreakMinuteOptions = []int{3, 5, 10, 15}
+	longBreakMinuteOptions  = []int{10, 15, 20, 25, 30}
+	pomodoroCycleOptions    = []int{2, 3, 4, 5, 6, 8}
+)
+
+// phaseColors are the big clock colors of the session phases
+var phaseColors = map[timer.PhaseKind]string{
+	timer.PhaseWork:       "#FF5555",
+	timer.PhaseShortBreak: "#00FF00",
+	timer.PhaseLongBreak:  "#00FFFF",
+	timer.PhaseRest:       "#FFFF00",
+	timer.PhaseFinished:   "#888888",
+}
+
+// sessionItemBack returns the index of the Back entry of the session setup menu
+func (m Model) sessionItemBack() int {
+	return sessionItemIntervals + len(m.app.config.Session.Intervals)
+}
+
+// openSession shows the running session, or the setup menu if there is none
+func (m Model) openSession() {
+	m.app.selectedMenu = 0
+	if _, running := m.app.timerManager.GetSession(); running {
+		m.app.state = StateSession
+		return
+	}
+	m.app.state = StateSessionSetup
+}
+
+// handleSessionSetupEnter starts a program or changes a Pomodoro setting
+func (m Model) handleSessionSetupEnter() {
+	session := &m.app.config.Session
+	work, shortBreak, longBreak, cycles := session.Pomodoro()
+
+	switch index := m.app.selectedMenu; {
+	case index == sessionItemPomodoro:
+		m.startSession(timer.PomodoroProgram(work, shortBreak, longBreak, cycles))
+	case index == sessionItemWork:
+		session.WorkMinutes = cycleOption(int(work.Minutes()), workMinuteOptions)
+		m.app.config.Save()
+	case index == sessionItemShortBreak:
+		session.ShortBreakMinutes = cycleOption(int(shortBreak.Minutes()), shortBreakMinuteOptions)
+		m.app.config.Save()
+	case index == sessionItemLongBreak:
+		session.LongBreakMinutes = cycleOption(int(longBreak.Minutes()), longBreakMinuteOptions)
+		m.app.config.Save()
+	case index == sessionItemCycles:
+		session.Cycles = cycleOption(cycles, pomodoroCycleOptions)
+		m.app.config.Save()
+	case index < m.sessionItemBack():
+		p := session.Intervals[index-sessionItemIntervals]
+		m.startSession(timer.IntervalProgram(p.Name, p.Rounds,
+			time.Duration(p.WorkSeconds)*time.Second, time.Duration(p.RestSeconds)*time.Second))
+	default: // Back
+		m.app.state = StateMainClock
+		m.app.selectedMenu = menuFocus
+	}
+}
+
+// startSession starts a program and switches to the session view
+func (m Model) startSession(program timer.Program) {
+	if m.app.timerManager.StartSession(program) {
+		m.app.state = StateSession
+	}
+}
+
+// handleSessionSetupKey handles the add/delete shortcuts for interval programs
+func (m Model) handleSessionSetupKey(key string) (tea.Model, tea.Cmd) {
+	session := &m.app.config.Session
+
+	switch key {
+	case "a": // Add interval program
+		m.app.state = StateIntervalInput
+		m.app.customPathInput = ""
+		m.app.inputError = ""
+	case "x", "delete": // Delete the selected interval program
+		index := m.app.selectedMenu - sessionItemIntervals
+		if index >= 0 && index < len(session.Intervals) {
+			session.Intervals = slices.Delete(session.Intervals, index, index+1)
+			m.app.config.Save()
+		}
+	}
+
+	return m, nil
+}
+
+// addIntervalInput validates the interval input and adds the program.
+// It returns the position of the new program in the session setup menu.
+func (m Model) addIntervalInput() (int, bool) {
+	program, err := config.ParseIntervalProgram(m.app.customPathInput)
+	if err != nil {
+		m.app.inputError = err.Error()
+		return 0, false
+	}
+
+	session := &m.app.config.Session
+	session.Intervals = append(session.Intervals, program)
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return sessionItemIntervals + len(session.Intervals) - 1, true
+}
+
+// handleSessionKey handles the shortcuts of the session view
+func (m Model) handleSessionKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "p": // Pause/resume
+		m.toggleSession()
+	case "n": // Next phase
+		m.app.timerManager.SkipPhase()
+	case "x": // Stop the session
+		m.app.timerManager.StopSession()
+		m.app.state = StateSessionSetup
+		m.app.selectedMenu = 0
+	}
+	return m, nil
+}
+
+// toggleSession pauses or resumes the running session
+func (m Model) toggleSession() {
+	if session, running := m.app.timerManager.GetSession(); running && session.Paused {
+		m.app.timerManager.ResumeSession()
+	} else {
+		m.app.timerManager.PauseSession()
+	}
+}
+
+// sessionStatusText returns the main clock note for a running session, or an empty string
+func (m Model) sessionStatusText(now time.Time) string {
+	session, running := m.app.timerManager.GetSession()
+	if !running {
+		return ""
+	}
+
+	phase := session.Phase()
+	text := fmt.Sprintf("🍅 %s %s %s", phase.Kind.Title(), session.Round(), timer.FormatTimeRemaining(session.TimeLeft(now)))
+	if session.Paused {
+		text += " PAUSED"
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(phaseColors[phase.Kind])).
+		Render(text)
+}
+
+// Render the Pomodoro settings and interval programs
+func (m Model) renderSessionSetup() string {
+	session := &m.app.config.Session
+	work, shortBreak, longBreak, cycles := session.Pomodoro()
+
+	options := []string{
+		fmt.Sprintf("Start Pomodoro: %d× %v work", cycles, work),
+		fmt.Sprintf("Work: %d min", int(work.Minutes())),
+		fmt.Sprintf("Short Break: %d min", int(shortBreak.Minutes())),
+		fmt.Sprintf("Long Break: %d min", int(longBreak.Minutes())),
+		fmt.Sprintf("Cycles: %d", cycles),
+	}
+	for _, program := range session.Intervals {
+		options = append(options, fmt.Sprintf("Start %s", program))
+	}
+	options = append(options, "Back")
+
+	return m.renderMenuWithInstructions(
+		"🍅 FOCUS",
+		options,
+		"↑↓ to navigate  •  ENTER to start/change  •  A add interval program  •  X delete program  •  ESC to return",
+	)
+}
+
+// Render the running session full screen with the time left in the big clock font
+func (m Model) renderSession() string {
+	var content strings.Builder
+
+	session, running := m.app.timerManager.GetSession()
+	if !running {
+		return m.renderSessionSetup()
+	}
+
+	now := time.Now()
+	phase := session.Phase()
+	color := lipgloss.Color(phaseColors[phase.Kind])
+
+	title := fmt.Sprintf("%s  •  %s  •  ROUND %s", session.Program.Name, phase.Kind.Title(), session.Round())
+	if session.Paused {
+		title += "  •  PAUSED"
+	}
+	content.WriteString(lipgloss.NewStyle().Foreground(color).Bold(true).Render(title))
+	content.WriteString("\n\n")
+
+	bigText := timer.FormatTimeRemaining(session.TimeLeft(now))
+	if session.Finished() {
+		bigText = "DONE"
+	}
+	content.WriteString(m.app.timeStyle.Foreground(color).Render(figure.NewFigure(bigText, m.app.config.FontName, true).String()))
+	content.WriteString("\n\n")
+
+	if !session.Finished() && session.Index+1 < len(session.Program.Phases) {
+		next := session.Program.Phases[session.Index+1]
+		content.WriteString(fmt.Sprintf("Next: %s %v\n\n", next.Kind.Title(), next.Duration))
+	}
+
+	// Most recent completed cycles
+	log := m.app.timerManager.GetSessionLog()
+	if len(log) > 0 {
+		content.WriteString("Completed:\n")
+		for i := len(log) - 1; i >= 0 && i >= len(log)-sessionLogLines; i-- {
+			entry := log[i]
+			content.WriteString(fmt.Sprintf("   %s  %-12s %d/%d  %v\n",
+				entry.Time.Format("Mon 02 Jan 15:04"), entry.Program, entry.Round, entry.Rounds, entry.Duration))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(m.app.instructionStyle.Render("P/ENTER pause  •  N next phase  •  X stop session  •  ESC back (session keeps running)"))
+
+	return content.String()
+}
+
+// Render the interval program input screen
+func (m Model) renderIntervalInput() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("🏃 NEW INTERVAL PROGRAM"))
+	content.WriteString("\n\n")
+	content.WriteString("Enter a name, the rounds and the work / rest durations:\n")
+	content.WriteString("Examples: tabata 8x20s/10s, plank 5x1m/30s\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type program  •  ENTER to add  •  ESC to cancel"))
+
+	return content.String()
+}
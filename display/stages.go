@@ -0,0 +1,241 @@
+package display
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"wecker/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Stage edit menu entries
+const (
+	stageItemSource = iota
+	stageItemValue
+	stageItemVolume
+	stageItemDuration
+	stageItemBack
+)
+
+// stageSources are the selectable sources of an alarm stage, gentle ones first
+var stageSources = []config.AlarmSource{config.SourceSoother, config.SourceMP3, config.SourceRadio, config.SourceBuzzer}
+
+// stageDurationOptions are the selectable stage durations in minutes, 0 means until the alarm stops
+var stageDurationOptions = []int{0, 1, 2, 3, 5, 10, 15, 20, 30}
+
+// stagesSummary summarizes the escalating sequence of an alarm, e.g. "soother → buzzer"
+func stagesSummary(a *config.Alarm) string {
+	if len(a.Stages) == 0 {
+		return "none"
+	}
+	sources := make([]string, len(a.Stages))
+	for i, stage := range a.Stages {
+		sources[i] = string(stage.Source)
+	}
+	return strings.Join(sources, " → ")
+}
+
+// stageValueText returns the file name or URL of a stage for display
+func stageValueText(stage config.AlarmStage) string {
+	switch {
+	case stage.AlarmSourceValue == "":
+		return "<default>"
+	case stage.Source == config.SourceBuzzer || stage.Source == config.SourceSoother:
+		return filepath.Base(stage.AlarmSourceValue)
+	default:
+		return stage.AlarmSourceValue
+	}
+}
+
+// stageDurationText formats a stage duration where 0 means until the alarm stops
+func stageDurationText(minutes int) string {
+	if minutes == 0 {
+		return "until stopped"
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
+
+// getCurrentStage returns the stage being edited, or nil if there is none
+func (m Model) getCurrentStage() *config.AlarmStage {
+	a := m.getCurrentAlarm()
+	if m.app.editingStage < 0 || m.app.editingStage >= len(a.Stages) {
+		return nil
+	}
+	return &a.Stages[m.app.editingStage]
+}
+
+// handleStagesKey handles the add/delete shortcuts of the stage list
+func (m Model) handleStagesKey(key string) (tea.Model, tea.Cmd) {
+	a := m.getCurrentAlarm()
+
+	switch key {
+	case "a": // Add a stage that starts like the previous one but louder
+		stage := config.AlarmStage{
+			Source:           a.Source,
+			AlarmSourceValue: a.AlarmSourceValue,
+			Volume:           a.Volume,
+		}
+		if len(a.Stages) > 0 {
+			stage = a.Stages[len(a.Stages)-1]
+			stage.Volume = min(100, stage.Volume+20)
+			// The former last stage now needs a duration to hand over
+			if a.Stages[len(a.Stages)-1].DurationMinutes == 0 {
+				a.Stages[len(a.Stages)-1].DurationMinutes = 5
+			}
+		}
+		stage.DurationMinutes = 0
+		a.Stages = append(a.Stages, stage)
+		m.app.selectedMenu = len(a.Stages) - 1
+		m.saveAlarms()
+	case "x", "delete": // Delete
+		if m.app.selectedMenu < len(a.Stages) {
+			a.Stages = slices.Delete(a.Stages, m.app.selectedMenu, m.app.selectedMenu+1)
+			if m.app.selectedMenu >= len(a.Stages) && m.app.selectedMenu > 0 {
+				m.app.selectedMenu--
+			}
+			m.saveAlarms()
+		}
+	}
+
+	return m, nil
+}
+
+// handleStageEditEnter handles ENTER in the stage edit menu
+func (m Model) handleStageEditEnter() {
+	stage := m.getCurrentStage()
+	if stage == nil {
+		m.app.state = StateAlarmStages
+		return
+	}
+
+	switch m.app.selectedMenu {
+	case stageItemSource:
+		index := slices.Index(stageSources, stage.Source)
+		stage.Source = stageSources[(index+1)%len(stageSources)]
+		// Reset source value when changing source
+		stage.AlarmSourceValue = ""
+		m.saveAlarms()
+	case stageItemValue:
+		if stage.Source == config.SourceMP3 || stage.Source == config.SourceRadio {
+			m.app.state = StateAlarmStageValue
+			m.app.customPathInput = stage.AlarmSourceValue
+			return
+		}
+		m.cycleStageTone(stage)
+		m.saveAlarms()
+	case stageItemDuration:
+		stage.DurationMinutes = cycleOption(stage.DurationMinutes, stageDurationOptions)
+		m.saveAlarms()
+	case stageItemBack:
+		m.app.state = StateAlarmStages
+		m.app.selectedMenu = m.app.editingStage
+	}
+}
+
+// cycleStageTone selects the next .tone file of the stage's source directory
+func (m Model) cycleStageTone(stage *config.AlarmStage) {
+	dir := m.app.config.BuzzerDir
+	if stage.Source == config.SourceSoother {
+		dir = m.app.config.SootherDir
+	}
+
+	files := getAvailableFiles(stage.Source, m.app.config)
+	if len(files) == 0 {
+		return
+	}
+	index := slices.Index(files, filepath.Base(stage.AlarmSourceValue))
+	stage.AlarmSourceValue = dir + "/" + files[(index+1)%len(files)]
+}
+
+// adjustStageVolume adjusts the volume of the stage being edited
+func (m Model) adjustStageVolume(delta int) {
+	if stage := m.getCurrentStage(); stage != nil {
+		stage.Volume = adjustValue(stage.Volume, 1, 100, delta)
+		m.saveAlarms()
+	}
+}
+
+// Render the stage list of an escalating alarm
+func (m Model) renderAlarmStages() string {
+	var content strings.Builder
+
+	a := m.getCurrentAlarm()
+	content.WriteString(m.app.titleStyle.Render(fmt.Sprintf("📈 STAGES FOR %s", a.Name())))
+	content.WriteString("\n\n")
+	content.WriteString("Stages play in order until the alarm is stopped, each one replacing the alarm source\n\n")
+
+	if len(a.Stages) == 0 {
+		content.WriteString(fmt.Sprintf("   No stages, plays %s at %d%%\n", a.Source, a.Volume))
+	}
+
+	for i, stage := range a.Stages {
+		// The last stage plays until the alarm stops whatever its duration says
+		duration := stageDurationText(stage.DurationMinutes)
+		if i == len(a.Stages)-1 {
+			duration = stageDurationText(0)
+		}
+
+		line := fmt.Sprintf("%d. %-8s %-24s %3d%%  %s", i+1, stage.Source, stageValueText(stage), stage.Volume, duration)
+		if i == m.app.selectedMenu {
+			content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" > %s ", line)))
+		} else {
+			content.WriteString(fmt.Sprintf("   %s", line))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(m.app.instructionStyle.Render("↑↓ to navigate  •  ENTER to edit  •  A add  •  X delete  •  ESC to return"))
+
+	return content.String()
+}
+
+// Render the edit menu of a single stage
+func (m Model) renderAlarmStageEdit() string {
+	stage := m.getCurrentStage()
+	if stage == nil {
+		return m.renderAlarmStages()
+	}
+
+	duration := stageDurationText(stage.DurationMinutes)
+	if m.app.editingStage == len(m.getCurrentAlarm().Stages)-1 {
+		duration += " (last stage plays until stopped)"
+	}
+
+	options := []string{
+		fmt.Sprintf("Source: %s", stage.Source),
+		fmt.Sprintf("Sound: %s", stageValueText(*stage)),
+		fmt.Sprintf("Volume: %d%%", stage.Volume),
+		fmt.Sprintf("Duration: %s", duration),
+		"Back",
+	}
+
+	return m.renderMenuWithInstructions(
+		fmt.Sprintf("📈 STAGE %d OF %s", m.app.editingStage+1, m.getCurrentAlarm().Name()),
+		options,
+		"↑↓ to navigate  •  ENTER to change  •  ← → volume  •  ESC to return",
+	)
+}
+
+// Render the MP3 path / radio URL input of a stage
+func (m Model) renderAlarmStageValue() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render(fmt.Sprintf("📂 STAGE %d SOUND", m.app.editingStage+1)))
+	content.WriteString("\n\n")
+	content.WriteString("Enter an MP3 file path or a radio stream URL:\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type path  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
@@ -0,0 +1,158 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"wecker/timer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/common-nighthawk/go-figure"
+)
+
+// lapTablePageSize is the number of laps visible at once on the stopwatch screen
+const lapTablePageSize = 8
+
+// stopwatchRefresh is how often the running stopwatch is redrawn
+const stopwatchRefresh = 50 * time.Millisecond
+
+// stopwatchTickMsg redraws the running stopwatch between the one-second clock ticks
+type stopwatchTickMsg struct{}
+
+// stopwatchTick schedules the next redraw of the stopwatch screen unless one is pending
+func (m Model) stopwatchTick() tea.Cmd {
+	if m.app.stopwatchTicking {
+		return nil
+	}
+	m.app.stopwatchTicking = true
+	return tea.Tick(stopwatchRefresh, func(time.Time) tea.Msg {
+		return stopwatchTickMsg{}
+	})
+}
+
+// handleStopwatchTick keeps redrawing while the running stopwatch is on screen
+func (m Model) handleStopwatchTick() (tea.Model, tea.Cmd) {
+	m.app.stopwatchTicking = false
+	if m.app.state != StateStopwatch || !m.app.timerManager.GetStopwatch().Running {
+		return m, nil
+	}
+	return m, m.stopwatchTick()
+}
+
+// openStopwatch shows the stopwatch screen, a running stopwatch is picked up where it is
+func (m Model) openStopwatch() tea.Cmd {
+	m.app.state = StateStopwatch
+	m.app.selectedMenu = 0
+	m.app.stopwatchNote = ""
+	m.app.inputError = ""
+	return m.stopwatchTick()
+}
+
+// toggleStopwatch starts or stops the stopwatch
+func (m Model) toggleStopwatch() tea.Cmd {
+	if m.app.timerManager.StopStopwatch() {
+		return nil
+	}
+	m.app.timerManager.StartStopwatch()
+	return m.stopwatchTick()
+}
+
+// handleStopwatchKey handles the shortcuts of the stopwatch screen
+func (m Model) handleStopwatchKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "a": // Lap
+		if _, ok := m.app.timerManager.LapStopwatch(); ok {
+			m.app.selectedMenu = 0 // Newest lap on top
+		}
+	case "r": // Reset
+		m.app.timerManager.ResetStopwatch()
+		m.app.selectedMenu = 0
+		m.app.stopwatchNote = ""
+		m.app.inputError = ""
+	case "e": // Export laps
+		m.exportLaps()
+	}
+	return m, nil
+}
+
+// exportLaps writes the laps to a timestamped CSV file in the working directory
+func (m Model) exportLaps() {
+	laps := m.app.timerManager.GetStopwatch().Laps
+	if len(laps) == 0 {
+		m.app.inputError = "No laps to export"
+		m.app.stopwatchNote = ""
+		return
+	}
+
+	path := time.Now().Format("laps-20060102-150405.csv")
+	if err := timer.ExportLaps(path, laps); err != nil {
+		m.app.inputError = err.Error()
+		m.app.stopwatchNote = ""
+		return
+	}
+	m.app.inputError = ""
+	m.app.stopwatchNote = fmt.Sprintf("%d laps exported to %s", len(laps), path)
+}
+
+// Render the stopwatch with the lap table, newest lap first
+func (m Model) renderStopwatch() string {
+	var content strings.Builder
+
+	stopwatch := m.app.timerManager.GetStopwatch()
+	now := time.Now()
+
+	title := "⏱️ STOPWATCH"
+	if !stopwatch.Running && stopwatch.Stopped > 0 {
+		title += " (STOPPED)"
+	}
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+
+	elapsed := timer.FormatStopwatch(stopwatch.Elapsed(now))
+	content.WriteString(m.app.timeStyle.Render(figure.NewFigure(elapsed, m.app.config.FontName, true).String()))
+	content.WriteString("\n\n")
+
+	laps := stopwatch.Laps
+	if len(laps) == 0 {
+		content.WriteString("   No laps\n")
+	} else {
+		content.WriteString(fmt.Sprintf("   %-5s %-14s %s\n", "LAP", "LAP TIME", "TOTAL"))
+	}
+
+	start, end := scrollWindow(m.app.selectedMenu, len(laps), lapTablePageSize)
+	if start > 0 {
+		content.WriteString(m.app.instructionStyle.Render("   ↑ more"))
+		content.WriteString("\n")
+	}
+
+	for i := start; i < end; i++ {
+		lap := laps[len(laps)-1-i]
+		line := fmt.Sprintf("%-5d %-14s %s", lap.Number, timer.FormatStopwatch(lap.Split), timer.FormatStopwatch(lap.Total))
+		if i == m.app.selectedMenu {
+			content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" > %s ", line)))
+		} else {
+			content.WriteString(fmt.Sprintf("   %s", line))
+		}
+		content.WriteString("\n")
+	}
+
+	if end < len(laps) {
+		content.WriteString(m.app.instructionStyle.Render("   ↓ more"))
+		content.WriteString("\n")
+	}
+
+	if m.app.inputError != "" {
+		content.WriteString("\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+		content.WriteString("\n")
+	} else if m.app.stopwatchNote != "" {
+		content.WriteString("\n")
+		content.WriteString(m.app.instructionStyle.Render(m.app.stopwatchNote))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(m.app.instructionStyle.Render("ENTER start/stop  •  A lap  •  R reset  •  E export CSV  •  ↑↓ scroll laps  •  ESC to return (keeps running)"))
+
+	return content.String()
+}
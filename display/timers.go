@@ -0,0 +1,197 @@
+package display
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"wecker/config"
+	"wecker/timer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// countdownExtension is the time added to a countdown with +
+const countdownExtension = time.Minute
+
+// selectedCountdown returns the countdown under the cursor of the timers screen
+func (m Model) selectedCountdown() (timer.Timer, bool) {
+	countdowns := m.app.timerManager.GetCountdowns()
+	if m.app.selectedMenu < 0 || m.app.selectedMenu >= len(countdowns) {
+		return timer.Timer{}, false
+	}
+	return countdowns[m.app.selectedMenu], true
+}
+
+// handleTimersKey handles the shortcuts of the timers screen
+func (m Model) handleTimersKey(key string) (tea.Model, tea.Cmd) {
+	if key == "a" { // Add
+		m.app.state = StateTimerInput
+		m.app.customPathInput = ""
+		m.app.inputError = ""
+		return m, nil
+	}
+
+	countdown, ok := m.selectedCountdown()
+	if !ok {
+		return m, nil
+	}
+
+	switch key {
+	case "p": // Pause/resume
+		m.toggleCountdown(countdown)
+	case "+", "=": // Extend
+		m.app.timerManager.ExtendCountdown(countdown.ID, countdownExtension)
+	case "t": // Next finishing sound
+		m.app.timerManager.SetCountdownSound(countdown.ID, m.nextCountdownSound(countdown.Sound))
+	case "x", "delete": // Cancel or dismiss
+		m.app.timerManager.RemoveCountdown(countdown.ID)
+		if m.app.selectedMenu >= len(m.app.timerManager.GetCountdowns()) && m.app.selectedMenu > 0 {
+			m.app.selectedMenu--
+		}
+	}
+
+	return m, nil
+}
+
+// toggleCountdown pauses a running countdown or resumes a paused one
+func (m Model) toggleCountdown(countdown timer.Timer) {
+	if countdown.Paused {
+		m.app.timerManager.ResumeCountdown(countdown.ID)
+	} else {
+		m.app.timerManager.PauseCountdown(countdown.ID)
+	}
+}
+
+// nextCountdownSound returns the buzzer .tone file following sound
+func (m Model) nextCountdownSound(sound string) string {
+	files := getAvailableFiles(config.SourceBuzzer, m.app.config)
+	if len(files) == 0 {
+		return sound
+	}
+	index := slices.Index(files, filepath.Base(sound))
+	return m.app.config.BuzzerDir + "/" + files[(index+1)%len(files)]
+}
+
+// startCountdownInput starts the countdown typed into the timer input, e.g. "pasta 9m".
+// It returns the position of the new countdown on the timers screen.
+func (m Model) startCountdownInput() (int, bool) {
+	name, duration, err := timer.ParseCountdown(m.app.customPathInput)
+	if err != nil {
+		m.app.inputError = err.Error()
+		return 0, false
+	}
+
+	sound := ""
+	if files := getAvailableFiles(config.SourceBuzzer, m.app.config); len(files) > 0 {
+		sound = m.app.config.BuzzerDir + "/" + files[0]
+	}
+
+	id := m.app.timerManager.StartCountdown(name, duration, sound)
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return slices.IndexFunc(m.app.timerManager.GetCountdowns(), func(t timer.Timer) bool { return t.ID == id }), true
+}
+
+// countdownStatus returns the time left of a countdown, or its state if it isn't running
+func countdownStatus(countdown timer.Timer, now time.Time) string {
+	remaining := timer.FormatTimeRemaining(countdown.TimeLeft(now))
+	switch {
+	case !countdown.IsActive:
+		return "DONE"
+	case countdown.Paused:
+		return remaining + " PAUSED"
+	default:
+		return remaining
+	}
+}
+
+// Render the countdowns shown on the main clock, or an empty string if there are none
+func (m Model) renderCountdownStatus(now time.Time) string {
+	countdowns := m.app.timerManager.GetCountdowns()
+	if len(countdowns) == 0 {
+		return ""
+	}
+
+	var items []string
+	for _, countdown := range countdowns {
+		color := "#00FF00"
+		switch {
+		case !countdown.IsActive:
+			color = "#FF0000"
+		case countdown.Paused:
+			color = "#FFFF00"
+		}
+		items = append(items, lipgloss.NewStyle().
+			Foreground(lipgloss.Color(color)).
+			Bold(!countdown.IsActive).
+			Render(fmt.Sprintf("⏲️ %s: %s", countdown.Name, countdownStatus(countdown, now))))
+	}
+
+	return lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Render(strings.Join(items, "    "))
+}
+
+// Render the timers screen with live countdowns
+func (m Model) renderTimers() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("⏲️ TIMERS"))
+	content.WriteString("\n\n")
+
+	countdowns := m.app.timerManager.GetCountdowns()
+	if len(countdowns) == 0 {
+		content.WriteString("   No timers running\n")
+	}
+
+	now := time.Now()
+	for i, countdown := range countdowns {
+		sound := "<default>"
+		if countdown.Sound != "" {
+			sound = filepath.Base(countdown.Sound)
+		}
+
+		line := fmt.Sprintf("%-20s %-14s of %-8s  🔔 %s", countdown.Name, countdownStatus(countdown, now),
+			timer.FormatTimeRemaining(countdown.Duration), sound)
+		if i == m.app.selectedMenu {
+			content.WriteString(m.app.selectedStyle.Render(fmt.Sprintf(" > %s ", line)))
+		} else {
+			content.WriteString(fmt.Sprintf("   %s", line))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(m.app.instructionStyle.Render("↑↓ to navigate  •  A add  •  P/ENTER pause  •  + add 1 min  •  T sound  •  X remove  •  ESC to return"))
+
+	return content.String()
+}
+
+// Render the new timer input screen
+func (m Model) renderTimerInput() string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render("⏲️ NEW TIMER"))
+	content.WriteString("\n\n")
+	content.WriteString("Enter a name and a duration:\n")
+	content.WriteString("Examples: pasta 9m, laundry 45m, tea 3m30s, 10\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+
+	if m.app.inputError != "" {
+		content.WriteString("\n\n")
+		content.WriteString(m.app.errorStyle.Render(m.app.inputError))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(m.app.instructionStyle.Render("Type name and duration  •  ENTER to start  •  ESC to cancel"))
+
+	return content.String()
+}
@@ -0,0 +1,114 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"wecker/alarm"
+	"wecker/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// alarmTimeText returns the alarm time, followed by the zone abbreviation for alarms
+// pinned to a timezone, e.g. "07:00 JST"
+func alarmTimeText(a *config.Alarm) string {
+	if a.Timezone == "" {
+		return a.Time[:5]
+	}
+	return fmt.Sprintf("%s %s", a.Time[:5], time.Now().In(a.Location()).Format("MST"))
+}
+
+// timezoneText describes the timezone of an alarm and when it rings in local time
+func timezoneText(a *config.Alarm) string {
+	if a.Timezone == "" {
+		return "local"
+	}
+	if a.TimezoneError() != nil {
+		return fmt.Sprintf("%s (invalid, using local)", a.Timezone)
+	}
+	if next := alarm.NextOccurrence(a, time.Now()); !next.IsZero() {
+		return fmt.Sprintf("%s (rings %s local)", a.Timezone, next.Format("15:04"))
+	}
+	return a.Timezone
+}
+
+// parseTimezoneInput validates the timezone typed into customPathInput; an empty input means local time
+func (m Model) parseTimezoneInput() (string, bool) {
+	name := strings.TrimSpace(m.app.customPathInput)
+	if _, err := config.LoadTimezone(name); err != nil {
+		m.app.inputError = err.Error()
+		return "", false
+	}
+
+	m.app.customPathInput = ""
+	m.app.inputError = ""
+	return name, true
+}
+
+// renderSecondClock returns the time in the configured second timezone, or an empty string
+func (m Model) renderSecondClock(now time.Time) string {
+	name := m.app.config.SecondTimezone
+	loc, err := config.LoadTimezone(name)
+	if name == "" || err != nil {
+		return ""
+	}
+
+	there := now.In(loc)
+	text := fmt.Sprintf("🌐 %s  %s %s", strings.ReplaceAll(name, "_", " "), m.app.config.FormatTime(there), there.Format("MST"))
+	if there.YearDay() != now.YearDay() {
+		text += there.Format(" (Mon)")
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Render(text)
+}
+
+// Render a timezone input screen with live validation and the current time there
+func (m Model) renderTimezoneInput(title, description string) string {
+	var content strings.Builder
+
+	content.WriteString(m.app.titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString(description)
+	content.WriteString("\n")
+	content.WriteString("Examples: Europe/Berlin, America/New_York, Asia/Tokyo\n\n")
+
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Background(lipgloss.Color("#333333")).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" %s_ ", m.app.customPathInput)))
+	content.WriteString("\n\n")
+
+	name := strings.TrimSpace(m.app.customPathInput)
+	loc, err := config.LoadTimezone(name)
+	switch {
+	case err != nil:
+		content.WriteString(m.app.errorStyle.Render(err.Error()))
+		content.WriteString("\n\n")
+	case name != "":
+		content.WriteString(fmt.Sprintf("Time there: %s\n\n", time.Now().In(loc).Format("Mon 15:04 MST")))
+	}
+
+	content.WriteString(m.app.instructionStyle.Render("Type IANA name  •  ENTER to save  •  ESC to cancel"))
+
+	return content.String()
+}
+
+// Render the timezone input of the current alarm
+func (m Model) renderAlarmTimezoneInput() string {
+	return m.renderTimezoneInput(
+		fmt.Sprintf("🌍 TIMEZONE FOR %s", m.getCurrentAlarm().Name()),
+		"The alarm time refers to this timezone (leave empty for local time):",
+	)
+}
+
+// Render the second clock timezone input
+func (m Model) renderSecondTimezoneInput() string {
+	return m.renderTimezoneInput(
+		"🌐 SECOND CLOCK",
+		"Timezone shown below the main clock (leave empty to hide):",
+	)
+}
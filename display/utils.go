@@ -1,6 +1,7 @@
 package display
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -40,3 +41,21 @@ func getBoolText(value bool) string {
 	}
 	return "OFF"
 }
+
+// cycleOption returns the option following current, wrapping around at the end
+func cycleOption(current int, options []int) int {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// formatMinutesOption formats a minute setting where 0 means disabled
+func formatMinutesOption(minutes int) string {
+	if minutes == 0 {
+		return "OFF"
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
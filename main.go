@@ -6,23 +6,36 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Alarm timezones work without system zoneinfo
 	"wecker/alarm"
 	"wecker/audio"
 	"wecker/config"
 	"wecker/display"
+	"wecker/state"
 	"wecker/timer"
 )
 
 func main() {
+	// Subcommands run instead of the clock
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Load runtime state (last fired alarms, active alarms and running timers)
+	st, err := state.Load()
+	if err != nil {
+		log.Printf("Failed to load state, starting fresh: %v", err)
+	}
+
 	// Create managers and components
-	alarmManager := alarm.NewManager(cfg)
-	timerManager := timer.NewManager()
+	alarmManager := alarm.NewManager(cfg, st)
+	timerManager := timer.NewManager(st)
 	audioPlayer := audio.NewPlayer(cfg)
 	displayApp := display.NewApp(cfg, alarmManager, timerManager, audioPlayer)
 
@@ -32,17 +45,20 @@ func main() {
 			// Set focus to the triggered alarm
 			displayApp.SetFocus(alarmID)
 
-			// Play alarm sound
-			if err := audioPlayer.PlayAlarm(alarmCfg); err != nil {
-				log.Printf("Failed to play alarm %d: %v", alarmID, err)
-				// Fallback to buzzer if configured source fails
-				if alarmCfg.Source != config.SourceBuzzer {
-					fallbackAlarm := *alarmCfg
-					fallbackAlarm.Source = config.SourceBuzzer
-					audioPlayer.PlayAlarm(&fallbackAlarm)
-				}
+			// Play alarm sound, escalating alarms resume at their current stage after a snooze
+			playAlarmStage(audioPlayer, alarmID, alarmCfg, alarmManager.GetStage(alarmID))
+		},
+		OnPreAlarmStarted: func(alarmID int, alarmCfg *config.Alarm, deadline time.Time) {
+			log.Printf("Alarm %d wake window started, full volume at %v", alarmID, deadline.Format("15:04"))
+			displayApp.SetFocus(alarmID)
+			if err := audioPlayer.PlayPreAlarm(alarmCfg, deadline); err != nil {
+				log.Printf("Failed to play pre-alarm %d: %v", alarmID, err)
 			}
 		},
+		OnAlarmStageChanged: func(alarmID int, alarmCfg *config.Alarm, stage int) {
+			log.Printf("Alarm %d advanced to stage %d", alarmID, stage+1)
+			playAlarmStage(audioPlayer, alarmID, alarmCfg, stage)
+		},
 		OnAlarmSnoozed: func(alarmID int, duration time.Duration) {
 			log.Printf("Alarm %d snoozed for %v", alarmID, duration)
 			audioPlayer.Stop()
@@ -51,15 +67,42 @@ func main() {
 			log.Printf("Alarm %d stopped", alarmID)
 			audioPlayer.Stop()
 		},
+		OnAlarmMissed: func(alarmID int, occurrence time.Time) {
+			log.Printf("Alarm %d missed its occurrence at %v", alarmID, occurrence)
+		},
+		OnAlarmDisabled: func(alarmID int) {
+			// One-shot alarms are done once they rang
+			displayApp.DisableAlarm(alarmID)
+		},
+		OnAlarmEscalated: func(alarmID int, alarmCfg *config.Alarm) {
+			log.Printf("Alarm %d not answered, escalating to full volume", alarmID)
+			escalated := alarmCfg.StageAlarm(alarmManager.GetStage(alarmID))
+			escalated.Volume = 100
+			escalated.VolumeRamp = false
+			if err := audioPlayer.PlayAlarm(&escalated); err != nil {
+				log.Printf("Failed to play escalated alarm %d: %v", alarmID, err)
+			}
+		},
+	})
+
+	// A sleep timer running until the end of the file ends with the sleep audio
+	audioPlayer.SetOnSleepFinished(func() {
+		if timerManager.ExpireTimer(timer.TypeSleep) {
+			log.Println("Sleep audio finished, sleep timer ended")
+		}
 	})
 
 	// Set up callbacks for timer events
 	timerManager.SetCallbacks(timer.TimerCallbacks{
 		OnSleepTimerExpired: func() {
 			log.Println("Sleep timer expired, stopping audio")
+			audioPlayer.FadeSleepAudio(0)
 			audioPlayer.Stop()
 			// Sleep timer automatically stops when expired - no manual reset needed
 		},
+		OnSleepTimerTick: func(remaining time.Duration) {
+			audioPlayer.FadeSleepAudio(remaining)
+		},
 		OnSnoozeTimerExpired: func() {
 			log.Println("Snooze timer expired")
 		},
@@ -69,12 +112,46 @@ func main() {
 				// Start playing sleep audio
 				if err := audioPlayer.PlaySleepAudio(); err != nil {
 					log.Printf("Failed to play sleep audio: %v", err)
+					// Without audio a timer running until the end of the file would never end,
+					// e.g. when the file of a restored timer is gone
+					if t, ok := timerManager.GetTimer(timer.TypeSleep); ok && t.UntilEnd {
+						timerManager.ExpireTimer(timer.TypeSleep)
+					}
 				}
 			}
 		},
 		OnTimerStopped: func(timerType timer.TimerType) {
 			if timerType == timer.TypeSleep {
-				log.Println("Sleep timer stopped")
+				log.Println("Sleep timer stopped, stopping audio")
+				audioPlayer.Stop()
+			}
+		},
+		OnTimerPaused: func(timerType timer.TimerType, remaining time.Duration) {
+			if timerType == timer.TypeSleep {
+				log.Printf("Sleep timer paused with %v left", remaining)
+			}
+		},
+		OnTimerResumed: func(timerType timer.TimerType, remaining time.Duration) {
+			if timerType == timer.TypeSleep {
+				log.Printf("Sleep timer resumed with %v left", remaining)
+			}
+		},
+		OnTimerExtended: func(timerType timer.TimerType, remaining time.Duration) {
+			if timerType == timer.TypeSleep {
+				log.Printf("Sleep timer extended, %v left", remaining)
+			}
+		},
+		OnPhaseChanged: func(session timer.Session) {
+			phase := session.Phase()
+			log.Printf("%s session: %s %s", session.Program.Name, phase.Kind.Title(), session.Round())
+			if err := audioPlayer.PlayTone(cfg.Session.PhaseTone(string(phase.Kind))); err != nil {
+				log.Printf("Failed to play phase tone: %v", err)
+			}
+		},
+		OnCountdownExpired: func(countdown timer.Timer) {
+			log.Printf("Timer %q finished", countdown.Name)
+			if err := audioPlayer.PlayTone(countdown.Sound); err != nil {
+				log.Printf("Failed to play timer sound: %v", err)
 			}
 		},
 	})
@@ -96,6 +173,11 @@ func main() {
 			log.Printf("Failed to save configuration: %v", err)
 		}
 
+		// Save runtime state
+		if err := st.Save(); err != nil {
+			log.Printf("Failed to save state: %v", err)
+		}
+
 		// Stop audio
 		audioPlayer.Stop()
 
@@ -110,3 +192,17 @@ func main() {
 		log.Fatalf("Failed to run application: %v", err)
 	}
 }
+
+// playAlarmStage plays a stage of an alarm, falling back to the buzzer if its source fails
+func playAlarmStage(audioPlayer *audio.Player, alarmID int, alarmCfg *config.Alarm, stage int) {
+	if err := audioPlayer.PlayAlarmStage(alarmCfg, stage); err != nil {
+		log.Printf("Failed to play alarm %d: %v", alarmID, err)
+		// Fallback to buzzer if configured source fails
+		fallbackAlarm := alarmCfg.StageAlarm(stage)
+		if fallbackAlarm.Source != config.SourceBuzzer {
+			fallbackAlarm.Source = config.SourceBuzzer
+			fallbackAlarm.AlarmSourceValue = ""
+			audioPlayer.PlayAlarm(&fallbackAlarm)
+		}
+	}
+}
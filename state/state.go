@@ -0,0 +1,192 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"wecker/config"
+)
+
+// State holds runtime data that has to survive restarts. It is kept apart from
+// config.json so user settings are never rewritten by bookkeeping.
+type State struct {
+	LastFired    map[int]time.Time `json:"last_fired"`              // Alarm ID -> time the alarm last rang
+	ActiveAlarms []AlarmRecord     `json:"active_alarms,omitempty"` // Ringing, snoozed or waking alarms
+	Timers       []TimerRecord     `json:"timers,omitempty"`        // Running timers
+	SessionLog   []SessionLogEntry `json:"session_log,omitempty"`   // Completed Pomodoro and interval cycles, oldest first
+
+	mutex sync.Mutex
+}
+
+// maxSessionLog is the number of session log entries kept
+const maxSessionLog = 200
+
+// SessionLogEntry records a completed work phase of a Pomodoro or interval session
+type SessionLogEntry struct {
+	Time     time.Time     `json:"time"`     // When the work phase ended
+	Program  string        `json:"program"`  // e.g. "Pomodoro" or "Tabata"
+	Round    int           `json:"round"`    // 1-based round of the session
+	Rounds   int           `json:"rounds"`   // Rounds of the session
+	Duration time.Duration `json:"duration"` // Length of the work phase
+}
+
+// AlarmRecord is the persisted form of an active alarm. All times are absolute so
+// a snoozed alarm still rings on time after a restart.
+type AlarmRecord struct {
+	ID          int          `json:"id"`
+	Alarm       config.Alarm `json:"alarm"` // Snapshot of the alarm as it was triggered
+	State       int          `json:"state"` // alarm.AlarmState
+	StartTime   time.Time    `json:"start_time"`
+	RingStart   time.Time    `json:"ring_start"`
+	SnoozeUntil time.Time    `json:"snooze_until,omitzero"`
+	SnoozeCount int          `json:"snooze_count,omitempty"`
+	AutoSnoozes int          `json:"auto_snoozes,omitempty"`
+	Escalated   bool         `json:"escalated,omitempty"`
+	Stage       int          `json:"stage,omitempty"`
+	StageStart  time.Time    `json:"stage_start"`
+	Deadline    time.Time    `json:"deadline,omitzero"`
+}
+
+// TimerRecord is the persisted form of a running timer
+type TimerRecord struct {
+	Type      int           `json:"type"` // timer.TimerType
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+	EndTime   time.Time     `json:"end_time"`
+	Paused    bool          `json:"paused,omitempty"`
+	Remaining time.Duration `json:"remaining,omitempty"` // Time left while paused
+	UntilEnd  bool          `json:"until_end,omitempty"` // Sleep timer running until the end of the file
+
+	// Countdown timers only
+	ID    int    `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Sound string `json:"sound,omitempty"`
+
+	// Stopwatch only, Paused is set while it is held
+	Elapsed time.Duration   `json:"elapsed,omitempty"` // Time accumulated in earlier runs
+	Laps    []time.Duration `json:"laps,omitempty"`    // Elapsed time at each lap
+}
+
+// New returns an empty state
+func New() *State {
+	return &State{
+		LastFired: make(map[int]time.Time),
+	}
+}
+
+// Load loads the state from state.json, returning an empty state if there is none yet
+func Load() (*State, error) {
+	statePath := getStatePath()
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return New(), fmt.Errorf("failed to read state file: %v", err)
+	}
+
+	st := New()
+	if err := json.Unmarshal(data, st); err != nil {
+		return New(), fmt.Errorf("failed to parse state file: %v", err)
+	}
+	if st.LastFired == nil {
+		st.LastFired = make(map[int]time.Time)
+	}
+
+	return st, nil
+}
+
+// Save saves the state to state.json
+func (s *State) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %v", err)
+	}
+
+	statePath := getStatePath()
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated state behind
+	tmpPath := statePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	if err := os.Rename(tmpPath, statePath); err != nil {
+		return fmt.Errorf("failed to replace state file: %v", err)
+	}
+
+	return nil
+}
+
+// GetLastFired returns when the alarm last rang, or the zero time if never
+func (s *State) GetLastFired(alarmID int) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.LastFired[alarmID]
+}
+
+// SetLastFired records when the alarm rang
+func (s *State) SetLastFired(alarmID int, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.LastFired[alarmID] = t
+}
+
+// GetActiveAlarms returns the persisted active alarms
+func (s *State) GetActiveAlarms() []AlarmRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]AlarmRecord(nil), s.ActiveAlarms...)
+}
+
+// SetActiveAlarms replaces the persisted active alarms
+func (s *State) SetActiveAlarms(records []AlarmRecord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ActiveAlarms = records
+}
+
+// GetTimers returns the persisted running timers
+func (s *State) GetTimers() []TimerRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]TimerRecord(nil), s.Timers...)
+}
+
+// SetTimers replaces the persisted running timers
+func (s *State) SetTimers(records []TimerRecord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Timers = records
+}
+
+// AddSessionLog appends an entry to the session log, dropping the oldest beyond maxSessionLog
+func (s *State) AddSessionLog(entry SessionLogEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.SessionLog = append(s.SessionLog, entry)
+	if len(s.SessionLog) > maxSessionLog {
+		s.SessionLog = s.SessionLog[len(s.SessionLog)-maxSessionLog:]
+	}
+}
+
+// GetSessionLog returns the session log, oldest first
+func (s *State) GetSessionLog() []SessionLogEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SessionLogEntry(nil), s.SessionLog...)
+}
+
+// getStatePath returns the path to the state file
+func getStatePath() string {
+	return "state.json"
+}
@@ -0,0 +1,203 @@
+package timer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wecker/state"
+)
+
+// maxCountdown is the longest duration a countdown can be started or extended to
+const maxCountdown = 24 * time.Hour
+
+// ParseCountdown parses a countdown like "pasta 9m" or "laundry 1h30m" into its name and
+// duration. A bare number counts minutes; without a name the duration serves as name.
+func ParseCountdown(input string) (string, time.Duration, error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return "", 0, fmt.Errorf("enter a name and a duration, e.g. pasta 9m")
+	}
+
+	last := fields[len(fields)-1]
+	duration, err := time.ParseDuration(last)
+	if minutes, convErr := strconv.Atoi(last); convErr == nil {
+		duration, err = time.Duration(minutes)*time.Minute, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid duration %q, expected e.g. 9m, 1h30m or 90s", last)
+	}
+	if duration <= 0 || duration > maxCountdown {
+		return "", 0, fmt.Errorf("duration must be between 1s and %v", maxCountdown)
+	}
+
+	name := strings.Join(fields[:len(fields)-1], " ")
+	if name == "" {
+		name = last
+	}
+	return name, duration, nil
+}
+
+// StartCountdown starts a named countdown that plays sound when it finishes and returns its ID
+func (m *Manager) StartCountdown(name string, duration time.Duration, sound string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	countdown := &Timer{
+		Type:      TypeCountdown,
+		StartTime: now,
+		Duration:  duration,
+		EndTime:   now.Add(duration),
+		IsActive:  true,
+		ID:        m.nextID,
+		Name:      name,
+		Sound:     sound,
+	}
+	m.countdowns[countdown.ID] = countdown
+	m.nextID++
+	m.persist()
+
+	return countdown.ID
+}
+
+// PauseCountdown freezes a running countdown
+func (m *Manager) PauseCountdown(id int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	countdown, exists := m.countdowns[id]
+	if !exists || !countdown.IsActive || countdown.Paused {
+		return false
+	}
+
+	countdown.Remaining = countdown.TimeLeft(time.Now())
+	countdown.Paused = true
+	m.persist()
+	return true
+}
+
+// ResumeCountdown continues a paused countdown with the time it had left
+func (m *Manager) ResumeCountdown(id int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	countdown, exists := m.countdowns[id]
+	if !exists || !countdown.Paused {
+		return false
+	}
+
+	countdown.EndTime = time.Now().Add(countdown.Remaining)
+	countdown.Paused = false
+	countdown.Remaining = 0
+	m.persist()
+	return true
+}
+
+// ExtendCountdown adds time to a countdown; a finished countdown starts again with the extra time
+func (m *Manager) ExtendCountdown(id int, d time.Duration) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	countdown, exists := m.countdowns[id]
+	if !exists {
+		return false
+	}
+
+	now := time.Now()
+	if countdown.TimeLeft(now)+d > maxCountdown {
+		return false
+	}
+
+	switch {
+	case !countdown.IsActive:
+		countdown.IsActive = true
+		countdown.EndTime = now.Add(d)
+	case countdown.Paused:
+		countdown.Remaining += d
+	default:
+		countdown.EndTime = countdown.EndTime.Add(d)
+	}
+	countdown.Duration += d
+	m.persist()
+	return true
+}
+
+// SetCountdownSound changes the sound a countdown plays when it finishes
+func (m *Manager) SetCountdownSound(id int, sound string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	countdown, exists := m.countdowns[id]
+	if !exists {
+		return false
+	}
+
+	countdown.Sound = sound
+	m.persist()
+	return true
+}
+
+// RemoveCountdown cancels a countdown or dismisses a finished one
+func (m *Manager) RemoveCountdown(id int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.countdowns[id]; !exists {
+		return false
+	}
+
+	delete(m.countdowns, id)
+	m.persist()
+	return true
+}
+
+// GetCountdowns returns copies of all countdowns ordered by ID, finished ones included
+func (m *Manager) GetCountdowns() []Timer {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := make([]Timer, 0, len(m.countdowns))
+	for _, countdown := range m.countdowns {
+		result = append(result, *countdown)
+	}
+	slices.SortFunc(result, func(a, b Timer) int { return a.ID - b.ID })
+
+	return result
+}
+
+// checkCountdowns finishes countdowns that ran out (internal, assumes mutex is held).
+// Finished countdowns stay listed until they are removed or extended.
+func (m *Manager) checkCountdowns(now time.Time) {
+	for _, countdown := range m.countdowns {
+		if !countdown.IsActive || countdown.Paused || now.Before(countdown.EndTime) {
+			continue
+		}
+
+		countdown.IsActive = false
+		m.persist()
+
+		if m.callbacks.OnCountdownExpired != nil {
+			go m.callbacks.OnCountdownExpired(*countdown)
+		}
+	}
+}
+
+// restoreCountdown brings back a persisted countdown (internal, assumes mutex is held).
+// A countdown that ran out while wecker wasn't running is listed as finished.
+func (m *Manager) restoreCountdown(record state.TimerRecord, now time.Time) {
+	m.countdowns[record.ID] = &Timer{
+		Type:      TypeCountdown,
+		StartTime: record.StartTime,
+		Duration:  record.Duration,
+		EndTime:   record.EndTime,
+		IsActive:  record.Paused || record.EndTime.After(now),
+		ID:        record.ID,
+		Name:      record.Name,
+		Sound:     record.Sound,
+		Paused:    record.Paused,
+		Remaining: record.Remaining,
+	}
+	m.nextID = max(m.nextID, record.ID+1)
+}
@@ -0,0 +1,241 @@
+package timer
+
+import (
+	"fmt"
+	"time"
+	"wecker/state"
+)
+
+// PhaseKind identifies a phase of a Pomodoro or interval session. The values double
+// as keys of config.SessionConfig.PhaseTones.
+type PhaseKind string
+
+const (
+	PhaseWork       PhaseKind = "work"
+	PhaseShortBreak PhaseKind = "short_break"
+	PhaseLongBreak  PhaseKind = "long_break"
+	PhaseRest       PhaseKind = "rest"
+	PhaseFinished   PhaseKind = "finished" // Reported once the last phase is over
+)
+
+// Title returns the phase name shown on screen
+func (k PhaseKind) Title() string {
+	switch k {
+	case PhaseWork:
+		return "WORK"
+	case PhaseShortBreak:
+		return "SHORT BREAK"
+	case PhaseLongBreak:
+		return "LONG BREAK"
+	case PhaseRest:
+		return "REST"
+	default:
+		return "DONE"
+	}
+}
+
+// Phase is one step of a session program
+type Phase struct {
+	Kind     PhaseKind
+	Duration time.Duration
+	Round    int // 1-based round the phase belongs to
+}
+
+// Program is the sequence of phases a session runs through
+type Program struct {
+	Name   string
+	Rounds int
+	Phases []Phase
+}
+
+// PomodoroProgram builds a Pomodoro of cycles work phases with short breaks in between
+// and a long break at the end
+func PomodoroProgram(work, shortBreak, longBreak time.Duration, cycles int) Program {
+	program := Program{Name: "Pomodoro", Rounds: cycles}
+	for round := 1; round <= cycles; round++ {
+		program.Phases = append(program.Phases, Phase{Kind: PhaseWork, Duration: work, Round: round})
+		if round < cycles {
+			program.Phases = append(program.Phases, Phase{Kind: PhaseShortBreak, Duration: shortBreak, Round: round})
+		} else {
+			program.Phases = append(program.Phases, Phase{Kind: PhaseLongBreak, Duration: longBreak, Round: round})
+		}
+	}
+	return program
+}
+
+// IntervalProgram builds an interval training of rounds work phases, each followed by a
+// rest except the last one
+func IntervalProgram(name string, rounds int, work, rest time.Duration) Program {
+	program := Program{Name: name, Rounds: rounds}
+	for round := 1; round <= rounds; round++ {
+		program.Phases = append(program.Phases, Phase{Kind: PhaseWork, Duration: work, Round: round})
+		if round < rounds && rest > 0 {
+			program.Phases = append(program.Phases, Phase{Kind: PhaseRest, Duration: rest, Round: round})
+		}
+	}
+	return program
+}
+
+// Session is a running Pomodoro or interval program
+type Session struct {
+	Program   Program
+	Index     int // Current phase, len(Program.Phases) once finished
+	StartTime time.Time
+	PhaseEnd  time.Time
+	Paused    bool
+	Remaining time.Duration // Time left in the phase while paused
+}
+
+// Finished reports whether the session went through all its phases
+func (s *Session) Finished() bool {
+	return s.Index >= len(s.Program.Phases)
+}
+
+// Phase returns the current phase; a finished session reports PhaseFinished
+func (s *Session) Phase() Phase {
+	if s.Finished() {
+		return Phase{Kind: PhaseFinished, Round: s.Program.Rounds}
+	}
+	return s.Program.Phases[s.Index]
+}
+
+// Round describes the round of the current phase, e.g. "2/4"
+func (s *Session) Round() string {
+	return fmt.Sprintf("%d/%d", s.Phase().Round, s.Program.Rounds)
+}
+
+// TimeLeft returns the time left in the current phase
+func (s *Session) TimeLeft(now time.Time) time.Duration {
+	switch {
+	case s.Finished():
+		return 0
+	case s.Paused:
+		return s.Remaining
+	default:
+		return max(0, s.PhaseEnd.Sub(now))
+	}
+}
+
+// StartSession starts a session, replacing the one running
+func (m *Manager) StartSession(program Program) bool {
+	if len(program.Phases) == 0 {
+		return false
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.session = &Session{Program: program, StartTime: now, PhaseEnd: now.Add(program.Phases[0].Duration)}
+
+	if m.callbacks.OnPhaseChanged != nil {
+		go m.callbacks.OnPhaseChanged(*m.session)
+	}
+	return true
+}
+
+// StopSession ends the session, finished or not
+func (m *Manager) StopSession() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.session == nil {
+		return false
+	}
+	m.session = nil
+	return true
+}
+
+// PauseSession freezes the current phase
+func (m *Manager) PauseSession() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := m.session
+	if s == nil || s.Paused || s.Finished() {
+		return false
+	}
+	s.Remaining = s.TimeLeft(time.Now())
+	s.Paused = true
+	return true
+}
+
+// ResumeSession continues a paused phase with the time it had left
+func (m *Manager) ResumeSession() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := m.session
+	if s == nil || !s.Paused {
+		return false
+	}
+	s.PhaseEnd = time.Now().Add(s.Remaining)
+	s.Paused = false
+	s.Remaining = 0
+	return true
+}
+
+// SkipPhase ends the current phase early and moves on to the next one. Skipped work
+// phases are not logged as completed.
+func (m *Manager) SkipPhase() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := m.session
+	if s == nil || s.Finished() {
+		return false
+	}
+	s.Paused = false
+	s.Remaining = 0
+	m.advanceSession(time.Now())
+	return true
+}
+
+// GetSession returns a copy of the current session
+func (m *Manager) GetSession() (Session, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.session == nil {
+		return Session{}, false
+	}
+	return *m.session, true
+}
+
+// GetSessionLog returns the completed session cycles, oldest first
+func (m *Manager) GetSessionLog() []state.SessionLogEntry {
+	return m.state.GetSessionLog()
+}
+
+// checkSession moves the session on when its phase is over and logs completed work
+// phases (internal, assumes mutex is held)
+func (m *Manager) checkSession(now time.Time) {
+	s := m.session
+	for s != nil && !s.Paused && !s.Finished() && !now.Before(s.PhaseEnd) {
+		if phase := s.Phase(); phase.Kind == PhaseWork {
+			m.state.AddSessionLog(state.SessionLogEntry{
+				Time:     s.PhaseEnd,
+				Program:  s.Program.Name,
+				Round:    phase.Round,
+				Rounds:   s.Program.Rounds,
+				Duration: phase.Duration,
+			})
+			go m.state.Save()
+		}
+		// The next phase starts when the last one ended, so late checks don't add drift
+		m.advanceSession(s.PhaseEnd)
+	}
+}
+
+// advanceSession starts the next phase at the given time (internal, assumes mutex is held)
+func (m *Manager) advanceSession(start time.Time) {
+	s := m.session
+	s.Index++
+	if !s.Finished() {
+		s.PhaseEnd = start.Add(s.Phase().Duration)
+	}
+
+	if m.callbacks.OnPhaseChanged != nil {
+		go m.callbacks.OnPhaseChanged(*s)
+	}
+}
@@ -0,0 +1,174 @@
+package timer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"wecker/state"
+)
+
+// Lap is a lap of the stopwatch
+type Lap struct {
+	Number int
+	Split  time.Duration // Time of this lap alone
+	Total  time.Duration // Elapsed time when the lap was taken
+}
+
+// Stopwatch measures elapsed time with laps. It keeps running in the timer manager
+// while its view is closed and is persisted like the other timers (TypeStopwatch).
+type Stopwatch struct {
+	Running   bool
+	StartTime time.Time     // Start of the current run
+	Stopped   time.Duration // Time accumulated in earlier runs
+	Laps      []Lap
+}
+
+// Elapsed returns the total measured time
+func (s *Stopwatch) Elapsed(now time.Time) time.Duration {
+	if !s.Running {
+		return s.Stopped
+	}
+	return s.Stopped + now.Sub(s.StartTime)
+}
+
+// StartStopwatch starts or continues the stopwatch
+func (m *Manager) StartStopwatch() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stopwatch.Running {
+		return false
+	}
+	m.stopwatch.Running = true
+	m.stopwatch.StartTime = time.Now()
+	m.persist()
+	return true
+}
+
+// StopStopwatch holds the stopwatch, it continues from there when started again
+func (m *Manager) StopStopwatch() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.stopwatch.Running {
+		return false
+	}
+	m.stopwatch.Stopped = m.stopwatch.Elapsed(time.Now())
+	m.stopwatch.Running = false
+	m.persist()
+	return true
+}
+
+// LapStopwatch records a lap of the running stopwatch
+func (m *Manager) LapStopwatch() (Lap, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := &m.stopwatch
+	if !s.Running {
+		return Lap{}, false
+	}
+
+	total := s.Elapsed(time.Now())
+	lap := Lap{Number: len(s.Laps) + 1, Split: total, Total: total}
+	if len(s.Laps) > 0 {
+		lap.Split = total - s.Laps[len(s.Laps)-1].Total
+	}
+	s.Laps = append(s.Laps, lap)
+	m.persist()
+	return lap, true
+}
+
+// ResetStopwatch stops the stopwatch and clears its time and laps
+func (m *Manager) ResetStopwatch() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stopwatch = Stopwatch{}
+	m.persist()
+}
+
+// stopwatchRecord returns the persisted form of the stopwatch, or false if it is reset
+// (internal, assumes mutex is held)
+func (m *Manager) stopwatchRecord() (state.TimerRecord, bool) {
+	s := &m.stopwatch
+	if !s.Running && s.Stopped == 0 && len(s.Laps) == 0 {
+		return state.TimerRecord{}, false
+	}
+
+	record := state.TimerRecord{
+		Type:      int(TypeStopwatch),
+		StartTime: s.StartTime,
+		Paused:    !s.Running,
+		Elapsed:   s.Stopped,
+	}
+	for _, lap := range s.Laps {
+		record.Laps = append(record.Laps, lap.Total)
+	}
+	return record, true
+}
+
+// restoreStopwatch brings back the persisted stopwatch (internal, assumes mutex is held).
+// A running stopwatch counts the time wecker wasn't running as well.
+func (m *Manager) restoreStopwatch(record state.TimerRecord) {
+	m.stopwatch = Stopwatch{
+		Running:   !record.Paused,
+		StartTime: record.StartTime,
+		Stopped:   record.Elapsed,
+	}
+	var previous time.Duration
+	for i, total := range record.Laps {
+		m.stopwatch.Laps = append(m.stopwatch.Laps, Lap{Number: i + 1, Split: total - previous, Total: total})
+		previous = total
+	}
+}
+
+// GetStopwatch returns a copy of the stopwatch
+func (m *Manager) GetStopwatch() Stopwatch {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stopwatch := m.stopwatch
+	stopwatch.Laps = append([]Lap(nil), m.stopwatch.Laps...)
+	return stopwatch
+}
+
+// ExportLaps writes the laps as CSV with lap number, lap time and total time, both as
+// formatted time and in milliseconds
+func ExportLaps(path string, laps []Lap) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"lap", "lap_time", "total_time", "lap_ms", "total_ms"})
+	for _, lap := range laps {
+		w.Write([]string{
+			strconv.Itoa(lap.Number),
+			FormatStopwatch(lap.Split),
+			FormatStopwatch(lap.Total),
+			strconv.FormatInt(lap.Split.Milliseconds(), 10),
+			strconv.FormatInt(lap.Total.Milliseconds(), 10),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return file.Close()
+}
+
+// FormatStopwatch formats a stopwatch time as MM:SS.mmm, or H:MM:SS.mmm from one hour on
+func FormatStopwatch(d time.Duration) string {
+	ms := d.Milliseconds()
+	hours, minutes, seconds := ms/3_600_000, ms/60_000%60, ms/1000%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%03d", hours, minutes, seconds, ms%1000)
+	}
+	return fmt.Sprintf("%02d:%02d.%03d", minutes, seconds, ms%1000)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"time"
+	"wecker/state"
 )
 
 // TimerType represents different types of timers
@@ -12,20 +13,49 @@ type TimerType int
 const (
 	TypeSleep TimerType = iota
 	TypeSnooze
+	TypeCountdown // Named general-purpose timers, any number of them can run
+	TypeStopwatch // Counts up instead of down, see Stopwatch
 )
 
 // Timer represents an active timer
 type Timer struct {
 	Type      TimerType
 	StartTime time.Time
-	Duration  time.Duration
+	Duration  time.Duration // Total duration including extensions
 	EndTime   time.Time
 	IsActive  bool
+	Paused    bool          // EndTime is meaningless while paused
+	Remaining time.Duration // Time left while paused
+	UntilEnd  bool          // No end time, runs until ExpireTimer is called
+
+	// Countdown timers only
+	ID    int
+	Name  string
+	Sound string // .tone file played when the countdown finishes
 }
 
-// Manager manages sleep and snooze timers
+// TimeLeft returns how much time the timer has left at the given time
+func (t *Timer) TimeLeft(now time.Time) time.Duration {
+	switch {
+	case !t.IsActive:
+		return 0
+	case t.Paused:
+		return t.Remaining
+	case t.UntilEnd:
+		return 0
+	default:
+		return max(0, t.EndTime.Sub(now))
+	}
+}
+
+// Manager manages sleep, snooze and countdown timers and the stopwatch
 type Manager struct {
 	activeTimers map[TimerType]*Timer
+	countdowns   map[int]*Timer // Countdown ID -> running, paused or finished countdown
+	nextID       int
+	session      *Session // Pomodoro or interval session, nil if none
+	stopwatch    Stopwatch
+	state        *state.State
 	mutex        sync.RWMutex
 	callbacks    TimerCallbacks
 }
@@ -33,15 +63,25 @@ type Manager struct {
 // TimerCallbacks defines callback functions for timer events
 type TimerCallbacks struct {
 	OnSleepTimerExpired  func()
+	OnSleepTimerTick     func(remaining time.Duration) // Every second while the sleep timer runs
 	OnSnoozeTimerExpired func()
 	OnTimerStarted       func(timerType TimerType, duration time.Duration)
 	OnTimerStopped       func(timerType TimerType)
+	OnTimerPaused        func(timerType TimerType, remaining time.Duration)
+	OnTimerResumed       func(timerType TimerType, remaining time.Duration)
+	OnTimerExtended      func(timerType TimerType, remaining time.Duration)
+	OnCountdownExpired   func(countdown Timer)
+	// OnPhaseChanged is called when a session starts, enters its next phase or finishes
+	OnPhaseChanged func(session Session)
 }
 
-// NewManager creates a new timer manager
-func NewManager() *Manager {
+// NewManager creates a new timer manager; running timers are kept in st across restarts
+func NewManager(st *state.State) *Manager {
 	return &Manager{
 		activeTimers: make(map[TimerType]*Timer),
+		countdowns:   make(map[int]*Timer),
+		nextID:       1,
+		state:        st,
 	}
 }
 
@@ -50,11 +90,95 @@ func (m *Manager) SetCallbacks(callbacks TimerCallbacks) {
 	m.callbacks = callbacks
 }
 
-// Start begins the timer monitoring loop
+// Start restores the timers that were running before a restart and begins the
+// timer monitoring loop
 func (m *Manager) Start() {
+	m.restore(time.Now())
 	go m.monitorLoop()
 }
 
+// restore resumes persisted timers that haven't ended yet. They keep their original
+// end time, the start callback receives the time that is left. Paused timers come
+// back paused.
+func (m *Manager) restore(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, record := range m.state.GetTimers() {
+		switch TimerType(record.Type) {
+		case TypeCountdown:
+			m.restoreCountdown(record, now)
+			continue
+		case TypeStopwatch:
+			m.restoreStopwatch(record)
+			continue
+		}
+		if !record.Paused && !record.UntilEnd && !record.EndTime.After(now) {
+			continue
+		}
+
+		timerType := TimerType(record.Type)
+		timer := &Timer{
+			Type:      timerType,
+			StartTime: record.StartTime,
+			Duration:  record.Duration,
+			EndTime:   record.EndTime,
+			IsActive:  true,
+			Paused:    record.Paused,
+			Remaining: record.Remaining,
+			UntilEnd:  record.UntilEnd,
+		}
+		m.activeTimers[timerType] = timer
+
+		if m.callbacks.OnTimerStarted != nil {
+			go m.callbacks.OnTimerStarted(timerType, timer.TimeLeft(now))
+		}
+	}
+
+	m.persist()
+}
+
+// persist writes the running timers to the state file (internal, assumes mutex is held)
+func (m *Manager) persist() {
+	records := make([]state.TimerRecord, 0, len(m.activeTimers)+len(m.countdowns)+1)
+	for _, timer := range m.activeTimers {
+		if !timer.IsActive {
+			continue
+		}
+		records = append(records, state.TimerRecord{
+			Type:      int(timer.Type),
+			StartTime: timer.StartTime,
+			Duration:  timer.Duration,
+			EndTime:   timer.EndTime,
+			Paused:    timer.Paused,
+			Remaining: timer.Remaining,
+			UntilEnd:  timer.UntilEnd,
+		})
+	}
+	for _, countdown := range m.countdowns {
+		if !countdown.IsActive {
+			continue
+		}
+		records = append(records, state.TimerRecord{
+			Type:      int(TypeCountdown),
+			StartTime: countdown.StartTime,
+			Duration:  countdown.Duration,
+			EndTime:   countdown.EndTime,
+			ID:        countdown.ID,
+			Name:      countdown.Name,
+			Sound:     countdown.Sound,
+			Paused:    countdown.Paused,
+			Remaining: countdown.Remaining,
+		})
+	}
+	if record, ok := m.stopwatchRecord(); ok {
+		records = append(records, record)
+	}
+
+	m.state.SetTimers(records)
+	go m.state.Save()
+}
+
 // monitorLoop continuously checks for timer expiration
 func (m *Manager) monitorLoop() {
 	ticker := time.NewTicker(1 * time.Second)
@@ -73,44 +197,70 @@ func (m *Manager) checkTimers(now time.Time) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	for timerType, timer := range m.activeTimers {
-		if timer.IsActive && now.After(timer.EndTime) {
-			timer.IsActive = false
-
-			// Call appropriate callback
-			switch timerType {
-			case TypeSleep:
-				if m.callbacks.OnSleepTimerExpired != nil {
-					go m.callbacks.OnSleepTimerExpired()
-				}
-			case TypeSnooze:
-				if m.callbacks.OnSnoozeTimerExpired != nil {
-					go m.callbacks.OnSnoozeTimerExpired()
-				}
-			}
-
-			// Remove expired timer
-			delete(m.activeTimers, timerType)
+	for _, timer := range m.activeTimers {
+		if timer.IsActive && !timer.Paused && !timer.UntilEnd && now.After(timer.EndTime) {
+			m.expire(timer)
+		}
+	}
+
+	if sleep, exists := m.activeTimers[TypeSleep]; exists && !sleep.Paused && !sleep.UntilEnd && m.callbacks.OnSleepTimerTick != nil {
+		go m.callbacks.OnSleepTimerTick(sleep.TimeLeft(now))
+	}
+
+	m.checkCountdowns(now)
+	m.checkSession(now)
+}
+
+// expire ends a timer and calls its expiry callback (internal, assumes mutex is held)
+func (m *Manager) expire(timer *Timer) {
+	timer.IsActive = false
+
+	// Call appropriate callback
+	switch timer.Type {
+	case TypeSleep:
+		if m.callbacks.OnSleepTimerExpired != nil {
+			go m.callbacks.OnSleepTimerExpired()
+		}
+	case TypeSnooze:
+		if m.callbacks.OnSnoozeTimerExpired != nil {
+			go m.callbacks.OnSnoozeTimerExpired()
 		}
 	}
+
+	// Remove expired timer
+	delete(m.activeTimers, timer.Type)
+	m.persist()
 }
 
-// StartSleepTimer starts a sleep timer with specified minutes
-func (m *Manager) StartSleepTimer(minutes int) bool {
-	// Accept 0 to disable, or 5-120 minutes for active timer
-	if minutes == 0 {
+// ExpireTimer ends a timer as if it had run out, e.g. a sleep timer running until
+// the end of the file once playback has finished
+func (m *Manager) ExpireTimer(timerType TimerType) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	timer, exists := m.activeTimers[timerType]
+	if !exists || !timer.IsActive {
+		return false
+	}
+
+	m.expire(timer)
+	return true
+}
+
+// StartSleepTimer starts a sleep timer with the given duration, 0 stops the sleep timer
+func (m *Manager) StartSleepTimer(duration time.Duration) bool {
+	if duration == 0 {
 		// Stop any active sleep timer when set to 0
 		return m.StopTimer(TypeSleep)
 	}
 
-	if minutes < 5 || minutes > 120 {
+	if duration < 0 || duration > maxCountdown {
 		return false
 	}
 
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	duration := time.Duration(minutes) * time.Minute
 	now := time.Now()
 
 	timer := &Timer{
@@ -122,6 +272,7 @@ func (m *Manager) StartSleepTimer(minutes int) bool {
 	}
 
 	m.activeTimers[TypeSleep] = timer
+	m.persist()
 
 	// Call start callback
 	if m.callbacks.OnTimerStarted != nil {
@@ -131,6 +282,28 @@ func (m *Manager) StartSleepTimer(minutes int) bool {
 	return true
 }
 
+// StartSleepTimerUntilEnd starts a sleep timer without end time, it runs until
+// ExpireTimer is called when the sleep audio has finished
+func (m *Manager) StartSleepTimerUntilEnd() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.activeTimers[TypeSleep] = &Timer{
+		Type:      TypeSleep,
+		StartTime: time.Now(),
+		IsActive:  true,
+		UntilEnd:  true,
+	}
+	m.persist()
+
+	// Call start callback
+	if m.callbacks.OnTimerStarted != nil {
+		go m.callbacks.OnTimerStarted(TypeSleep, 0)
+	}
+
+	return true
+}
+
 // StartSnoozeTimer starts a snooze timer with specified minutes
 func (m *Manager) StartSnoozeTimer(minutes int) bool {
 	m.mutex.Lock()
@@ -148,6 +321,7 @@ func (m *Manager) StartSnoozeTimer(minutes int) bool {
 	}
 
 	m.activeTimers[TypeSnooze] = timer
+	m.persist()
 
 	// Call start callback
 	if m.callbacks.OnTimerStarted != nil {
@@ -169,6 +343,7 @@ func (m *Manager) StopTimer(timerType TimerType) bool {
 
 	timer.IsActive = false
 	delete(m.activeTimers, timerType)
+	m.persist()
 
 	// Call stop callback
 	if m.callbacks.OnTimerStopped != nil {
@@ -178,22 +353,107 @@ func (m *Manager) StopTimer(timerType TimerType) bool {
 	return true
 }
 
+// PauseTimer holds a running timer; it doesn't expire until resumed
+func (m *Manager) PauseTimer(timerType TimerType) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	timer, exists := m.activeTimers[timerType]
+	if !exists || !timer.IsActive || timer.Paused || timer.UntilEnd {
+		return false
+	}
+
+	timer.Remaining = timer.TimeLeft(time.Now())
+	timer.Paused = true
+	m.persist()
+
+	if m.callbacks.OnTimerPaused != nil {
+		go m.callbacks.OnTimerPaused(timerType, timer.Remaining)
+	}
+
+	return true
+}
+
+// ResumeTimer continues a paused timer with the time it had left
+func (m *Manager) ResumeTimer(timerType TimerType) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	timer, exists := m.activeTimers[timerType]
+	if !exists || !timer.IsActive || !timer.Paused {
+		return false
+	}
+
+	remaining := timer.Remaining
+	timer.EndTime = time.Now().Add(remaining)
+	timer.Paused = false
+	timer.Remaining = 0
+	m.persist()
+
+	if m.callbacks.OnTimerResumed != nil {
+		go m.callbacks.OnTimerResumed(timerType, remaining)
+	}
+
+	return true
+}
+
+// ExtendTimer adds time to a running or paused timer
+func (m *Manager) ExtendTimer(timerType TimerType, d time.Duration) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	timer, exists := m.activeTimers[timerType]
+	if !exists || !timer.IsActive || timer.UntilEnd || d <= 0 {
+		return false
+	}
+
+	timer.Duration += d
+	if timer.Paused {
+		timer.Remaining += d
+	} else {
+		timer.EndTime = timer.EndTime.Add(d)
+	}
+	m.persist()
+
+	if m.callbacks.OnTimerExtended != nil {
+		go m.callbacks.OnTimerExtended(timerType, timer.TimeLeft(time.Now()))
+	}
+
+	return true
+}
+
 // GetTimeRemaining returns the remaining time for a specific timer
 func (m *Manager) GetTimeRemaining(timerType TimerType) time.Duration {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	timer, exists := m.activeTimers[timerType]
-	if !exists || !timer.IsActive {
+	if !exists {
 		return 0
 	}
 
-	remaining := time.Until(timer.EndTime)
-	if remaining < 0 {
-		return 0
+	return timer.TimeLeft(time.Now())
+}
+
+// GetTimer returns a copy of an active timer
+func (m *Manager) GetTimer(timerType TimerType) (Timer, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	timer, exists := m.activeTimers[timerType]
+	if !exists || !timer.IsActive {
+		return Timer{}, false
 	}
+	return *timer, true
+}
+
+// IsTimerPaused checks if a specific timer is active but paused
+func (m *Manager) IsTimerPaused(timerType TimerType) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
 
-	return remaining
+	timer, exists := m.activeTimers[timerType]
+	return exists && timer.IsActive && timer.Paused
 }
 
 // IsTimerActive checks if a specific timer is currently active
@@ -205,34 +465,11 @@ func (m *Manager) IsTimerActive(timerType TimerType) bool {
 	return exists && timer.IsActive
 }
 
-// GetSleepTimerOptions returns valid sleep timer duration options in minutes
-func GetSleepTimerOptions() []int {
-	return []int{5, 7, 15, 30, 45, 60, 90, 120}
-}
-
 // GetSnoozeTimerOptions returns valid snooze timer duration options in minutes
 func GetSnoozeTimerOptions() []int {
 	return []int{5, 7, 15, 30, 45, 60, 90, 120}
 }
 
-// CycleSleepTimer cycles through sleep timer options and returns the next option
-func CycleSleepTimer(currentMinutes int) int {
-	options := GetSleepTimerOptions()
-
-	// Find current option index
-	currentIndex := -1
-	for i, option := range options {
-		if option == currentMinutes {
-			currentIndex = i
-			break
-		}
-	}
-
-	// Move to next option (cycle back to start if at end)
-	nextIndex := (currentIndex + 1) % len(options)
-	return options[nextIndex]
-}
-
 // CycleSnoozeTimer cycles through snooze timer options and returns the next option
 func CycleSnoozeTimer(currentMinutes int) int {
 	options := GetSnoozeTimerOptions()
@@ -251,10 +488,14 @@ func CycleSnoozeTimer(currentMinutes int) int {
 	return options[nextIndex]
 }
 
-// FormatTimeRemaining formats remaining time as MM:SS
+// FormatTimeRemaining formats remaining time as MM:SS, or H:MM:SS from one hour on
 func FormatTimeRemaining(duration time.Duration) string {
 	totalSeconds := int(duration.Seconds())
-	minutes := totalSeconds / 60
+	hours := totalSeconds / 3600
+	minutes := totalSeconds / 60 % 60
 	seconds := totalSeconds % 60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
 	return fmt.Sprintf("%02d:%02d", minutes, seconds)
 }
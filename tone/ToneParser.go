@@ -2,17 +2,11 @@ package tone
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
-	"math"
-	"math/rand"
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
-
-	"github.com/hajimehoshi/oto/v2"
 )
 
 const (
@@ -133,6 +127,14 @@ var noteMap = map[string]float64{
 	"NOTE_DS8": NOTE_DS8,
 }
 
+// Limits of a program; the synth schedules every note up front, so a runaway repeat
+// would use up all memory
+const (
+	maxProgramLength = 24 * time.Hour
+	maxRepeatCount   = 10000
+	maxProgramNotes  = 500000 // Commands after unrolling repeats
+)
+
 type WaveType int
 
 const (
@@ -141,6 +143,15 @@ const (
 	Sawtooth
 )
 
+// Envelope shapes the level of every note: it rises to full level over Attack, falls
+// to the Sustain level over Decay and fades out over the last Release of the note
+type Envelope struct {
+	Attack  time.Duration
+	Decay   time.Duration
+	Sustain float64 // Fraction of full level
+	Release time.Duration
+}
+
 type Command struct {
 	Type     string
 	Freq     float64
@@ -148,26 +159,20 @@ type Command struct {
 	Count    int
 	Commands []Command
 	Wave     WaveType
+	Level    float64   // Level of the following notes for gain
+	Envelope *Envelope // Envelope of the following notes, nil to turn it off
 }
 
-func PlayToneFile(filename string) {
-	ctx, ready, err := oto.NewContext(44100, 1, 2)
-	if err != nil {
-		panic(err)
-	}
-	<-ready
-
-	//fmt.Print("Pattern-Datei eingeben: ")
-	//var filename string
-	//fmt.Scanln(&filename)
+// Program is a parsed .tone file
+type Program []Command
 
+// ParseFile parses a .tone file
+func ParseFile(filename string) (Program, error) {
 	commands, err := parseToneFile(filename)
 	if err != nil {
-		fmt.Printf("Fehler: %v\n", err)
-		return
+		return nil, fmt.Errorf("%s: %v", filename, err)
 	}
-
-	executeCommands(ctx, commands)
+	return commands, nil
 }
 
 func parseToneFile(filename string) ([]Command, error) {
@@ -183,7 +188,60 @@ func parseToneFile(filename string) ([]Command, error) {
 		content.WriteString(scanner.Text() + " ")
 	}
 
-	return parseToneCommands(content.String())
+	return parseProgram(content.String())
+}
+
+// parseProgram parses the commands of a .tone file and checks the program limits
+func parseProgram(input string) ([]Command, error) {
+	commands, err := parseToneCommands(input)
+	if err != nil {
+		return nil, err
+	}
+
+	length, notes := programSize(commands)
+	if length > maxProgramLength {
+		return nil, fmt.Errorf("program plays longer than %v", maxProgramLength)
+	}
+	if notes > maxProgramNotes {
+		return nil, fmt.Errorf("program has more than %d commands once repeats are unrolled", maxProgramNotes)
+	}
+	return commands, nil
+}
+
+// programSize returns the length of commands and how many commands they unroll to.
+// Both are capped just above their limit, so nested repeats can't overflow.
+func programSize(commands []Command) (time.Duration, int) {
+	var length time.Duration
+	var notes int
+	exceeded := func() bool { return length > maxProgramLength || notes > maxProgramNotes }
+
+	for _, cmd := range commands {
+		notes++
+		switch cmd.Type {
+		case "tone", "wave", "noise", "delay":
+			length += cmd.Duration
+		case "repeat":
+			blockLength, blockNotes := programSize(cmd.Commands)
+			length += blockLength * time.Duration(cmd.Count)
+			notes += blockNotes * cmd.Count
+		case "parallel":
+			var longest time.Duration
+			for _, branch := range cmd.Commands {
+				branchLength, branchNotes := programSize([]Command{branch})
+				longest = max(longest, branchLength)
+				notes += branchNotes
+			}
+			length += longest
+		case "fadein", "fadeout":
+			blockLength, blockNotes := programSize(cmd.Commands)
+			length += blockLength
+			notes += blockNotes
+		}
+		if exceeded() {
+			break
+		}
+	}
+	return min(length, maxProgramLength+1), min(notes, maxProgramNotes+1)
 }
 
 func parseToneCommands(input string) ([]Command, error) {
@@ -194,7 +252,7 @@ func parseToneCommands(input string) ([]Command, error) {
 		switch tokens[i] {
 		case "tone":
 			if i+2 >= len(tokens) {
-				return nil, fmt.Errorf("tone braucht 2 Parameter")
+				return nil, fmt.Errorf("tone needs 2 parameters")
 			}
 			freq, err := parseFreq(tokens[i+1])
 			if err != nil {
@@ -209,7 +267,7 @@ func parseToneCommands(input string) ([]Command, error) {
 
 		case "sine", "square", "sawtooth":
 			if i+2 >= len(tokens) {
-				return nil, fmt.Errorf("%s braucht 2 Parameter", tokens[i])
+				return nil, fmt.Errorf("%s needs 2 parameters", tokens[i])
 			}
 			freq, err := parseFreq(tokens[i+1])
 			if err != nil {
@@ -233,7 +291,7 @@ func parseToneCommands(input string) ([]Command, error) {
 
 		case "noise":
 			if i+1 >= len(tokens) {
-				return nil, fmt.Errorf("noise braucht 1 Parameter")
+				return nil, fmt.Errorf("noise needs 1 parameter")
 			}
 			duration, err := parseDuration(tokens[i+1])
 			if err != nil {
@@ -244,7 +302,7 @@ func parseToneCommands(input string) ([]Command, error) {
 
 		case "delay":
 			if i+1 >= len(tokens) {
-				return nil, fmt.Errorf("delay braucht 1 Parameter")
+				return nil, fmt.Errorf("delay needs 1 parameter")
 			}
 			duration, err := parseDuration(tokens[i+1])
 			if err != nil {
@@ -253,13 +311,57 @@ func parseToneCommands(input string) ([]Command, error) {
 			commands = append(commands, Command{Type: "delay", Duration: duration})
 			i += 2
 
+		case "envelope":
+			if i+1 < len(tokens) && tokens[i+1] == "off" {
+				commands = append(commands, Command{Type: "envelope"})
+				i += 2
+				break
+			}
+			if i+4 >= len(tokens) {
+				return nil, fmt.Errorf("envelope syntax: envelope ATTACK DECAY SUSTAIN RELEASE or envelope off")
+			}
+			envelope, err := parseEnvelope(tokens[i+1 : i+5])
+			if err != nil {
+				return nil, err
+			}
+			commands = append(commands, Command{Type: "envelope", Envelope: envelope})
+			i += 5
+
+		case "gain":
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("gain needs 1 parameter")
+			}
+			level, err := parseLevel(tokens[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("gain: %v", err)
+			}
+			commands = append(commands, Command{Type: "gain", Level: level})
+			i += 2
+
+		case "fadein", "fadeout":
+			if i+2 >= len(tokens) || tokens[i+2] != "{" {
+				return nil, fmt.Errorf("%s syntax: %s DURATION { ... }", tokens[i], tokens[i])
+			}
+			duration, err := parseDuration(tokens[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			fadeCommands, newI, err := parseBlock(tokens, i+3)
+			if err != nil {
+				return nil, err
+			}
+
+			commands = append(commands, Command{Type: tokens[i], Duration: duration, Commands: fadeCommands})
+			i = newI
+
 		case "loop", "repeat":
 			if i+2 >= len(tokens) || tokens[i+2] != "{" {
 				return nil, fmt.Errorf("%s syntax: %s COUNT { ... }", tokens[i], tokens[i])
 			}
 			count, err := strconv.Atoi(tokens[i+1])
-			if err != nil {
-				return nil, err
+			if err != nil || count < 0 || count > maxRepeatCount {
+				return nil, fmt.Errorf("%s count must be between 0 and %d, got %q", tokens[i], maxRepeatCount, tokens[i+1])
 			}
 
 			loopCommands, newI, err := parseBlock(tokens, i+3)
@@ -304,7 +406,7 @@ func parseBlock(tokens []string, start int) ([]Command, int, error) {
 	}
 
 	if braceCount > 0 {
-		return nil, 0, fmt.Errorf("schließende } fehlt")
+		return nil, 0, fmt.Errorf("missing closing }")
 	}
 
 	blockContent := strings.Join(tokens[start:end-1], " ")
@@ -323,98 +425,42 @@ func parseFreq(s string) (float64, error) {
 	return strconv.ParseFloat(s, 64)
 }
 
-func parseDuration(s string) (time.Duration, error) {
-	s = strings.TrimSuffix(s, "ms")
-	ms, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, err
+// parseEnvelope parses the ATTACK DECAY SUSTAIN RELEASE parameters of envelope
+func parseEnvelope(params []string) (*Envelope, error) {
+	var envelope Envelope
+	var err error
+	if envelope.Attack, err = parseDuration(params[0]); err != nil {
+		return nil, err
 	}
-	return time.Duration(ms) * time.Millisecond, nil
-}
-
-func executeCommands(ctx *oto.Context, commands []Command) {
-	for _, cmd := range commands {
-		switch cmd.Type {
-		case "tone":
-			playTone(ctx, cmd.Freq, cmd.Duration, Sine)
-		case "wave":
-			playTone(ctx, cmd.Freq, cmd.Duration, cmd.Wave)
-		case "noise":
-			playNoise(ctx, cmd.Duration)
-		case "delay":
-			time.Sleep(cmd.Duration)
-		case "repeat":
-			for i := 0; i < cmd.Count; i++ {
-				executeCommands(ctx, cmd.Commands)
-			}
-		case "parallel":
-			executeParallel(ctx, cmd.Commands)
-		}
+	if envelope.Decay, err = parseDuration(params[1]); err != nil {
+		return nil, err
 	}
-}
-
-func executeParallel(ctx *oto.Context, commands []Command) {
-	var wg sync.WaitGroup
-
-	for _, cmd := range commands {
-		wg.Add(1)
-		go func(c Command) {
-			defer wg.Done()
-			executeCommands(ctx, []Command{c})
-		}(cmd)
+	if envelope.Sustain, err = parseLevel(params[2]); err != nil {
+		return nil, fmt.Errorf("envelope sustain: %v", err)
 	}
-
-	wg.Wait()
+	if envelope.Release, err = parseDuration(params[3]); err != nil {
+		return nil, err
+	}
+	return &envelope, nil
 }
 
-func playTone(ctx *oto.Context, frequency float64, duration time.Duration, wave WaveType) {
-	sampleRate := 44100
-	samples := int(float64(sampleRate) * duration.Seconds())
-	data := make([]byte, samples*2)
-
-	for i := 0; i < samples; i++ {
-		var sample float64
-		t := float64(i) / float64(sampleRate)
-
-		switch wave {
-		case Sine:
-			sample = math.Sin(2 * math.Pi * frequency * t)
-		case Square:
-			if math.Sin(2*math.Pi*frequency*t) > 0 {
-				sample = 1.0
-			} else {
-				sample = -1.0
-			}
-		case Sawtooth:
-			sample = 2 * (frequency*t - math.Floor(frequency*t+0.5))
-		}
-
-		value := int16(sample * 32767 * 0.15)
-		data[i*2] = byte(value)
-		data[i*2+1] = byte(value >> 8)
+// parseLevel parses a level between 0 and 1
+func parseLevel(s string) (float64, error) {
+	level, err := strconv.ParseFloat(s, 64)
+	if err != nil || level < 0 || level > 1 {
+		return 0, fmt.Errorf("level must be between 0 and 1, got %q", s)
 	}
-
-	player := ctx.NewPlayer(bytes.NewReader(data))
-	player.Play()
-	time.Sleep(duration)
-	player.Close()
+	return level, nil
 }
 
-func playNoise(ctx *oto.Context, duration time.Duration) {
-	sampleRate := 44100
-	samples := int(float64(sampleRate) * duration.Seconds())
-	data := make([]byte, samples*2)
-
-	for i := 0; i < samples; i++ {
-		sample := rand.Float64()*2 - 1       // -1 bis 1
-		value := int16(sample * 32767 * 0.1) // Leiser für Rauschen
-
-		data[i*2] = byte(value)
-		data[i*2+1] = byte(value >> 8)
+// parseDuration parses a duration in milliseconds, with or without the ms suffix
+func parseDuration(s string) (time.Duration, error) {
+	ms, err := strconv.Atoi(strings.TrimSuffix(s, "ms"))
+	if err != nil {
+		return 0, err
 	}
-
-	player := ctx.NewPlayer(bytes.NewReader(data))
-	player.Play()
-	time.Sleep(duration)
-	player.Close()
+	if ms < 0 || ms > int(maxProgramLength/time.Millisecond) {
+		return 0, fmt.Errorf("duration must be between 0 and %v, got %q", maxProgramLength, s)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
 }
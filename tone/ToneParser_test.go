@@ -0,0 +1,49 @@
+package tone
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		length  time.Duration
+		wantErr string // Part of the expected error, empty if the program is valid
+	}{
+		{name: "notes and delays", input: "tone 440 100 delay 50ms sine NOTE_A4 100", length: 250 * time.Millisecond},
+		{name: "repeat", input: "repeat 3 { square 1000 100 delay 100 }", length: 600 * time.Millisecond},
+		{name: "parallel takes the longest branch", input: "parallel { tone 440 100 tone 660 300 }", length: 300 * time.Millisecond},
+		{name: "zero duration", input: "tone 440 0", length: 0},
+		{name: "repeat at the limit", input: "repeat 10000 { tone 440 1 }", length: 10 * time.Second},
+		{name: "negative duration", input: "tone 440 -100", wantErr: "duration must be between"},
+		{name: "negative delay", input: "tone 440 100 delay -500ms", wantErr: "duration must be between"},
+		{name: "negative envelope time", input: "envelope -10 10 0.5 10", wantErr: "duration must be between"},
+		{name: "duration beyond the program length", input: "noise 100000000", wantErr: "duration must be between"},
+		{name: "negative repeat count", input: "repeat -1 { tone 440 100 }", wantErr: "repeat count must be between"},
+		{name: "repeat count above the limit", input: "repeat 100000000 { tone 440 100 }", wantErr: "repeat count must be between"},
+		{name: "nested repeats too long", input: "repeat 10000 { repeat 10000 { tone 440 1000 } }", wantErr: "program plays longer than"},
+		{name: "nested repeats with too many commands", input: "repeat 10000 { repeat 10000 { gain 1 } }", wantErr: "once repeats are unrolled"},
+		{name: "deeply nested repeats", input: "repeat 10000 { repeat 10000 { repeat 10000 { repeat 10000 { repeat 10000 { tone 440 0 } } } } }", wantErr: "once repeats are unrolled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseProgram(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseProgram(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseProgram(%q): %v", tt.input, err)
+			}
+			if length, _ := programSize(program); length != tt.length {
+				t.Errorf("length = %v, want %v", length, tt.length)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package tone
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/oto/v2"
+)
+
+const (
+	sampleRate = 44100                  // Sample rate of the audio output in Hz
+	drainPoll  = 10 * time.Millisecond  // Interval of checks whether the output has played everything
+	maxDrain   = 500 * time.Millisecond // Limit for waiting on buffered output after a program
+)
+
+// Engine plays tone programs on the audio output. oto allows only one output context
+// per process, so all playback shares the engine returned by Output.
+type Engine struct {
+	context *oto.Context
+}
+
+var (
+	outputOnce   sync.Once
+	outputEngine *Engine
+	outputErr    error
+)
+
+// Output returns the engine of the audio output, opening the output on first use
+func Output() (*Engine, error) {
+	outputOnce.Do(func() {
+		ctx, ready, err := oto.NewContext(sampleRate, 1, 2)
+		if err != nil {
+			outputErr = fmt.Errorf("failed to open audio output: %v", err)
+			return
+		}
+		<-ready
+
+		outputEngine = &Engine{context: ctx}
+	})
+	return outputEngine, outputErr
+}
+
+// PlayFile parses a .tone file and plays it at unity gain, see Play
+func PlayFile(ctx context.Context, filename string) error {
+	program, err := ParseFile(filename)
+	if err != nil {
+		return err
+	}
+	engine, err := Output()
+	if err != nil {
+		return err
+	}
+	return engine.Play(ctx, program, nil)
+}
+
+// Play plays a program and returns once it has finished. gain controls the volume while
+// the program plays, nil plays at unity gain. When ctx is cancelled the sound stops
+// right away and ctx.Err() is returned.
+func (e *Engine) Play(ctx context.Context, program Program, gain *Gain) error {
+	synth := NewSynth(program, gain)
+	player := e.context.NewPlayer(synth)
+
+	player.Play()
+	if wait(ctx, synth.Duration()) {
+		// Let the output play what it has buffered
+		drainEnd := time.Now().Add(maxDrain)
+		for player.IsPlaying() && time.Now().Before(drainEnd) && wait(ctx, drainPoll) {
+		}
+	}
+	player.Pause()
+	player.Close()
+	return ctx.Err()
+}
+
+// wait sleeps for d and reports false if ctx was cancelled before
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
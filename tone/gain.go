@@ -0,0 +1,47 @@
+package tone
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Tones used to play at a fixed level whatever the volume. That level, unity gain, is
+// kept at the default alarm volume so existing alarms don't get quieter; louder volumes
+// boost up to maxGain and rely on the synthesizer's soft clipper.
+const (
+	unityVolume = 50
+	maxGain     = 4 // Gain at volume 100, (100/unityVolume)²
+)
+
+// Gain is a volume control that can be changed while a program plays. The synthesizer
+// follows changes smoothly, so there are no clicks when the volume steps.
+type Gain struct {
+	bits atomic.Uint64
+}
+
+// NewGain returns a gain control set to value
+func NewGain(value float64) *Gain {
+	g := &Gain{}
+	g.Set(value)
+	return g
+}
+
+// Set changes the gain, clamped to [0, maxGain]
+func (g *Gain) Set(value float64) {
+	g.bits.Store(math.Float64bits(max(0, min(maxGain, value))))
+}
+
+// Value returns the current gain, 1 for a nil control
+func (g *Gain) Value() float64 {
+	if g == nil {
+		return 1
+	}
+	return math.Float64frombits(g.bits.Load())
+}
+
+// VolumeGain converts a volume of 1-100 into a gain, 1 at unityVolume. The curve is
+// squared so equal volume steps sound like roughly equal changes in loudness.
+func VolumeGain(volume int) float64 {
+	v := float64(max(0, min(100, volume))) / unityVolume
+	return v * v
+}
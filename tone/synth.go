@@ -0,0 +1,290 @@
+package tone
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// Gain staging of the synthesizer. Voices are mixed at their own level, the mix passes
+// a soft clipper so overlapping voices never wrap around or clip hard.
+const (
+	toneLevel      = 0.15                  // Level of a single note
+	noiseLevel     = 0.1                   // Level of noise, quieter as it carries more energy
+	clipKnee       = 0.8                   // Mix level above which the soft clipper starts to compress
+	declickTime    = 5 * time.Millisecond  // Fade in and out of every voice to avoid clicks
+	gainSmoothing  = 10 * time.Millisecond // Time constant of gain changes
+	bytesPerSample = 2                     // 16-bit mono
+)
+
+// voice is a note or noise scheduled at an exact sample position
+type voice struct {
+	start, end int64 // Sample range [start, end)
+	freq       float64
+	wave       WaveType
+	noise      bool
+	level      float64
+	envelope   *envelope
+	fades      []fade
+}
+
+// envelope is an Envelope in samples
+type envelope struct {
+	attack, decay, release int64
+	sustain                float64
+}
+
+// fade is a linear fade in or out over the sample range [from, to) of a block
+type fade struct {
+	from, to int64
+	in       bool
+}
+
+// shape is the gain and envelope set by directives for the following notes of a block
+// and the blocks nested in it
+type shape struct {
+	level    float64
+	envelope *Envelope
+}
+
+// Synth renders a program as 16-bit little-endian mono PCM, at the output sample rate
+// unless rendered offline.
+// All voices are mixed into one stream, so parallel voices, repeats and delays are
+// exact to the sample. The mix is scaled by a live gain control and soft-clipped. It
+// implements io.Reader and returns io.EOF after the program.
+type Synth struct {
+	voices   []voice // Sorted by start
+	next     int     // Index of the first voice that hasn't started yet
+	active   []voice // Voices sounding at pos
+	rate     float64 // Samples per second
+	pos      int64   // Next sample to render
+	length   int64   // Length of the program in samples
+	duration time.Duration
+	noise    *rand.Rand
+	gain     *Gain   // Master volume, nil plays at unity gain
+	level    float64 // Smoothed value of gain
+}
+
+// NewSynth schedules the voices of a program for the audio output; gain may be nil for
+// unity gain
+func NewSynth(program Program, gain *Gain) *Synth {
+	return newSynth(program, sampleRate, gain)
+}
+
+// newSynth schedules the voices of a program at the given sample rate. Noise is seeded
+// the same every time, so a program always renders to the same samples.
+func newSynth(program Program, rate int, gain *Gain) *Synth {
+	s := &Synth{rate: float64(rate), noise: rand.New(rand.NewSource(1)), gain: gain, level: gain.Value()}
+	s.duration = s.schedule(program, 0, shape{level: 1})
+	s.length = s.samplesAt(s.duration)
+	slices.SortStableFunc(s.voices, func(a, b voice) int {
+		return int(a.start - b.start)
+	})
+	return s
+}
+
+// Duration returns the length of the program
+func (s *Synth) Duration() time.Duration {
+	return s.duration
+}
+
+// samplesAt converts a time into the program to a sample position
+func (s *Synth) samplesAt(d time.Duration) int64 {
+	return int64(math.Round(d.Seconds() * s.rate))
+}
+
+// schedule adds the voices of commands starting at start and returns when they end.
+// Positions are derived from absolute times, so rounding never accumulates. Directives
+// change the shape for the rest of the block only.
+func (s *Synth) schedule(commands []Command, start time.Duration, sh shape) time.Duration {
+	at := start
+	for _, cmd := range commands {
+		switch cmd.Type {
+		case "tone":
+			s.addVoice(voice{freq: cmd.Freq, wave: Sine, level: toneLevel}, at, cmd.Duration, sh)
+			at += cmd.Duration
+		case "wave":
+			s.addVoice(voice{freq: cmd.Freq, wave: cmd.Wave, level: toneLevel}, at, cmd.Duration, sh)
+			at += cmd.Duration
+		case "noise":
+			s.addVoice(voice{noise: true, level: noiseLevel}, at, cmd.Duration, sh)
+			at += cmd.Duration
+		case "delay":
+			at += cmd.Duration
+		case "gain":
+			sh.level = cmd.Level
+		case "envelope":
+			sh.envelope = cmd.Envelope
+		case "repeat":
+			for i := 0; i < cmd.Count; i++ {
+				at = s.schedule(cmd.Commands, at, sh)
+			}
+		case "parallel":
+			// Every command of the block starts at the same time, the block ends with the longest
+			end := at
+			for _, branch := range cmd.Commands {
+				end = max(end, s.schedule([]Command{branch}, at, sh))
+			}
+			at = end
+		case "fadein", "fadeout":
+			first := len(s.voices)
+			end := s.schedule(cmd.Commands, at, sh)
+			f := fade{from: s.samplesAt(at), to: s.samplesAt(at + cmd.Duration), in: true}
+			if cmd.Type == "fadeout" {
+				f = fade{from: s.samplesAt(end - cmd.Duration), to: s.samplesAt(end)}
+			}
+			for i := first; i < len(s.voices); i++ {
+				s.voices[i].fades = append(s.voices[i].fades, f)
+			}
+			at = end
+		}
+	}
+	return at
+}
+
+// addVoice schedules a voice from start for duration with the shape of its block
+func (s *Synth) addVoice(v voice, start, duration time.Duration, sh shape) {
+	v.start, v.end = s.samplesAt(start), s.samplesAt(start+duration)
+	v.level *= sh.level
+	if e := sh.envelope; e != nil {
+		v.envelope = &envelope{
+			attack:  s.samplesAt(e.Attack),
+			decay:   s.samplesAt(e.Decay),
+			sustain: e.Sustain,
+			release: s.samplesAt(e.Release),
+		}
+	}
+	if v.end > v.start {
+		s.voices = append(s.voices, v)
+	}
+}
+
+// Read renders the next samples into p
+func (s *Synth) Read(p []byte) (int, error) {
+	if s.pos >= s.length {
+		return 0, io.EOF
+	}
+
+	smoothing := 1 - math.Exp(-1/(gainSmoothing.Seconds()*s.rate))
+	target := s.gain.Value()
+
+	samples := min(int64(len(p)/bytesPerSample), s.length-s.pos)
+	for i := int64(0); i < samples; i++ {
+		s.level += (target - s.level) * smoothing
+		value := int16(softClip(s.mix()*s.level) * math.MaxInt16)
+		p[i*2] = byte(value)
+		p[i*2+1] = byte(value >> 8)
+		s.pos++
+	}
+
+	return int(samples) * bytesPerSample, nil
+}
+
+// mix returns the sum of all voices at pos
+func (s *Synth) mix() float64 {
+	for s.next < len(s.voices) && s.voices[s.next].start <= s.pos {
+		s.active = append(s.active, s.voices[s.next])
+		s.next++
+	}
+	s.active = slices.DeleteFunc(s.active, func(v voice) bool { return v.end <= s.pos })
+
+	sum := 0.0
+	for i := range s.active {
+		sum += s.sample(&s.active[i])
+	}
+	return sum
+}
+
+// sample returns the value of a voice at pos including its envelope, block fades and
+// declick fades
+func (s *Synth) sample(v *voice) float64 {
+	n := s.pos - v.start
+	var value float64
+	if v.noise {
+		value = s.noise.Float64()*2 - 1
+	} else {
+		t := float64(n) / s.rate
+		switch v.wave {
+		case Sine:
+			value = math.Sin(2 * math.Pi * v.freq * t)
+		case Square:
+			if math.Sin(2*math.Pi*v.freq*t) > 0 {
+				value = 1.0
+			} else {
+				value = -1.0
+			}
+		case Sawtooth:
+			value = 2 * (v.freq*t - math.Floor(v.freq*t+0.5))
+		}
+	}
+
+	// Short linear fades at both ends, at most a quarter of the voice each
+	length := v.end - v.start
+	ramp := min(s.samplesAt(declickTime), length/4)
+	if ramp > 0 {
+		if n < ramp {
+			value *= float64(n) / float64(ramp)
+		} else if left := length - n; left < ramp {
+			value *= float64(left) / float64(ramp)
+		}
+	}
+
+	if v.envelope != nil {
+		value *= v.envelope.level(n, length)
+	}
+	for _, f := range v.fades {
+		value *= f.level(s.pos)
+	}
+
+	return value * v.level
+}
+
+// level returns the envelope level n samples into a note of length samples. The release
+// scales whatever level the note has reached, so short notes still fade out smoothly.
+func (e *envelope) level(n, length int64) float64 {
+	level := e.sustain
+	switch {
+	case n < e.attack:
+		level = float64(n) / float64(e.attack)
+	case n < e.attack+e.decay:
+		level = 1 - (1-e.sustain)*float64(n-e.attack)/float64(e.decay)
+	}
+	if left := length - n; left < e.release {
+		level *= float64(left) / float64(e.release)
+	}
+	return level
+}
+
+// level returns the fade level at pos
+func (f fade) level(pos int64) float64 {
+	switch {
+	case pos < f.from:
+		if f.in {
+			return 0
+		}
+		return 1
+	case pos >= f.to:
+		if f.in {
+			return 1
+		}
+		return 0
+	}
+	progress := float64(pos-f.from) / float64(f.to-f.from)
+	if f.in {
+		return progress
+	}
+	return 1 - progress
+}
+
+// softClip passes levels up to clipKnee unchanged and bends louder ones smoothly
+// towards full scale
+func softClip(x float64) float64 {
+	magnitude := math.Abs(x)
+	if magnitude <= clipKnee {
+		return x
+	}
+	clipped := clipKnee + (1-clipKnee)*math.Tanh((magnitude-clipKnee)/(1-clipKnee))
+	return math.Copysign(clipped, x)
+}
@@ -0,0 +1,78 @@
+package tone
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Sample rates accepted for offline rendering
+const (
+	MinRenderRate = 8000
+	MaxRenderRate = 192000
+)
+
+// RenderWAV renders a program offline and writes it as a 16-bit mono PCM WAV file at
+// the given sample rate
+func RenderWAV(w io.Writer, program Program, rate int) error {
+	if rate < MinRenderRate || rate > MaxRenderRate {
+		return fmt.Errorf("sample rate must be between %d and %d Hz", MinRenderRate, MaxRenderRate)
+	}
+
+	synth := newSynth(program, rate, nil)
+	dataSize := synth.length * bytesPerSample
+	if dataSize > 0xFFFFFFFF-36 {
+		return fmt.Errorf("program is too long for a WAV file")
+	}
+
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16),                    // Size of the format chunk
+		uint16(1),                     // PCM
+		uint16(1),                     // Mono
+		uint32(rate),                  // Sample rate
+		uint32(rate * bytesPerSample), // Byte rate
+		uint16(bytesPerSample),        // Block align
+		uint16(16),                    // Bits per sample
+		[4]byte{'d', 'a', 't', 'a'},
+		uint32(dataSize),
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("failed to write WAV header: %v", err)
+		}
+	}
+
+	if _, err := io.Copy(w, synth); err != nil {
+		return fmt.Errorf("failed to write samples: %v", err)
+	}
+	return nil
+}
+
+// RenderFile renders a .tone file into a WAV file, see RenderWAV
+func RenderFile(toneFile, wavFile string, rate int) error {
+	program, err := ParseFile(toneFile)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(wavFile)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", wavFile, err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err := RenderWAV(w, program, rate); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", wavFile, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,94 @@
+package tone
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestRenderWAV(t *testing.T) {
+	program, err := parseToneCommands("square 1000 100ms delay 50ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const rate = 8000
+	var buf bytes.Buffer
+	if err := RenderWAV(&buf, program, rate); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	const samples = 1200 // 150ms at 8kHz
+	if len(data) != 44+samples*2 {
+		t.Fatalf("file is %d bytes, want %d", len(data), 44+samples*2)
+	}
+
+	header := []struct {
+		name   string
+		offset int
+		want   any
+	}{
+		{"RIFF id", 0, "RIFF"},
+		{"RIFF size", 4, uint32(36 + samples*2)},
+		{"WAVE id", 8, "WAVE"},
+		{"fmt id", 12, "fmt "},
+		{"fmt size", 16, uint32(16)},
+		{"format", 20, uint16(1)},
+		{"channels", 22, uint16(1)},
+		{"sample rate", 24, uint32(rate)},
+		{"byte rate", 28, uint32(rate * 2)},
+		{"block align", 32, uint16(2)},
+		{"bits per sample", 34, uint16(16)},
+		{"data id", 36, "data"},
+		{"data size", 40, uint32(samples * 2)},
+	}
+	for _, field := range header {
+		var got any
+		switch want := field.want.(type) {
+		case string:
+			got = string(data[field.offset : field.offset+len(want)])
+		case uint32:
+			got = binary.LittleEndian.Uint32(data[field.offset:])
+		case uint16:
+			got = binary.LittleEndian.Uint16(data[field.offset:])
+		}
+		if got != field.want {
+			t.Errorf("%s = %v, want %v", field.name, got, field.want)
+		}
+	}
+
+	sample := func(i int) int16 {
+		return int16(binary.LittleEndian.Uint16(data[44+i*2:]))
+	}
+
+	// The square wave plays at the note level between its declick fades
+	const level = 4915 // toneLevel of full scale
+	for i := 100; i < 700; i++ {
+		if s := sample(i); s != level && s != -level {
+			t.Fatalf("sample %d = %d, want ±%d", i, s, level)
+		}
+	}
+	// The declick fade starts from silence
+	if s := sample(0); s != 0 {
+		t.Errorf("sample 0 = %d, want 0", s)
+	}
+	// The delay is silent
+	for i := 800; i < samples; i++ {
+		if s := sample(i); s != 0 {
+			t.Fatalf("sample %d = %d, want 0", i, s)
+		}
+	}
+}
+
+func TestRenderWAVRate(t *testing.T) {
+	program, err := parseToneCommands("sine 440 10ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rate := range []int{0, MinRenderRate - 1, MaxRenderRate + 1} {
+		if err := RenderWAV(&bytes.Buffer{}, program, rate); err == nil {
+			t.Errorf("rate %d was accepted", rate)
+		}
+	}
+}